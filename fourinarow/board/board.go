@@ -1,10 +1,11 @@
 package board
 
-import "fmt"
+const WINNING_LENGTH int = 4
 
 type Board struct {
-	board [][]uint8
-	size  uint8
+	board               [][]uint8
+	size                uint8
+	winningCombinations [][][2]uint8 // All lines of places that win the game, only depends on the size
 }
 
 func NewBoard(boardSize uint8) *Board {
@@ -14,10 +15,29 @@ func NewBoard(boardSize uint8) *Board {
 		board[i] = make([]uint8, boardSize)
 	}
 
-	return &Board{
+	playBoard := &Board{
 		board: board,
 		size:  boardSize,
 	}
+
+	// Calculate the winning combinations once
+	playBoard.winningCombinations = playBoard.createWinningCombinations()
+	return playBoard
+}
+
+func (playBoard *Board) Copy() *Board {
+	// Copy the places, the winning combinations never change so they can be shared
+	board := make([][]uint8, playBoard.size)
+	for i := range board {
+		board[i] = make([]uint8, playBoard.size)
+		copy(board[i], playBoard.board[i])
+	}
+
+	return &Board{
+		board:               board,
+		size:                playBoard.size,
+		winningCombinations: playBoard.winningCombinations,
+	}
 }
 
 func (playBoard *Board) GetBoard() [][]uint8 {
@@ -43,6 +63,16 @@ func (playBoard *Board) LastSetPosition(row uint8) uint8 {
 	return index
 }
 
+func (playBoard *Board) GetLandedPosition(row uint8) int {
+	// Find the highest set position, this is where the last piece landed
+	for j := 0; j < int(playBoard.size); j++ {
+		if playBoard.board[row][j] != 0 {
+			return j
+		}
+	}
+	return -1
+}
+
 func (playBoard *Board) GetPosition(row uint8, col int) uint8 {
 	if col < 0 {
 		col = int(playBoard.LastSetPosition(row))
@@ -66,88 +96,73 @@ func (playBoard *Board) IsFull() bool {
 }
 
 func (playBoard *Board) CheckWin(player uint8) bool {
-	// Create list of possible winning combinations
-	/*
-				[
-					[[0,0], [0,1], [0,2]], Row #1
-					[[1,0], [1,1], [1,2]], Row #2
-					[[2,0], [2,1], [2,2]], Row #3
-		            [[0,0], [1,0], [2,0]], Col #1
-		            [[0,1], [1,1], [2,1]], Col #2
-		            [[0,2], [1,2], [2,2]], Col #3
-		            [[0,0], [1,1], [2,2]], Diagonal #1
-					[[2,0], [1,1], [0,2]], Diagonal #2
-				]
-	*/
-	boardSize := playBoard.GetBoardSize()
-	var winningCombinations [][][2]uint8
+	return playBoard.GetWinningLine(player) != nil
+}
 
-	// First up: rows
-	for i := 0; i < boardSize; i++ {
-		rowCombinations := [][2]uint8{}
-		for j := 0; j < boardSize; j++ {
-			rowCombinations = append(rowCombinations, [2]uint8{uint8(i), uint8(j)})
+func (playBoard *Board) GetWinningLine(player uint8) [][2]uint8 {
+	// Check each winning combination
+	// The same player should have all places in the a combination
+	for _, winningCombination := range playBoard.GetWinningCombinations() {
+		countPlaces := 0
+		for _, place := range winningCombination {
+			if playBoard.GetPosition(place[0], int(place[1])) == player {
+				countPlaces++
+			}
 		}
-		winningCombinations = append(winningCombinations, rowCombinations)
-	}
-
-	if len(winningCombinations) != boardSize {
-		fmt.Println("Error: winningCombinations #1 is not the same length as boardSize")
-		fmt.Println(winningCombinations)
-		return false
-	}
-
-	// Second up: columns
-	for i := 0; i < boardSize; i++ {
-		rowCombinations := [][2]uint8{}
-		for j := 0; j < boardSize; j++ {
-			rowCombinations = append(rowCombinations, [2]uint8{uint8(j), uint8(i)})
+		if countPlaces == len(winningCombination) {
+			return winningCombination
 		}
-		winningCombinations = append(winningCombinations, rowCombinations)
-	}
-
-	if len(winningCombinations) != boardSize*2 {
-		fmt.Println("Error: winningCombinations #2 is not the same length as boardSize")
-		fmt.Println(winningCombinations)
-		return false
-	}
-
-	// Third up: diagonals
-	// They need to be dynamic based on the size of the board
-	// First forward dialog
-	rowCombinations := [][2]uint8{}
-	for i := 0; i < boardSize; i++ {
-		rowCombinations = append(rowCombinations, [2]uint8{uint8(i), uint8(i)})
 	}
-	winningCombinations = append(winningCombinations, rowCombinations)
+	return nil
+}
 
-	// Backward diagonal
-	rowCombinations2 := [][2]uint8{}
-	for i := 0; i < boardSize; i++ {
-		rowCombinations2 = append(rowCombinations2, [2]uint8{uint8(i), uint8(boardSize - 1 - i)})
-	}
-	winningCombinations = append(winningCombinations, rowCombinations2)
+func (playBoard *Board) GetWinningLength() int {
+	// Four in a row, unless the board is too small for that
+	return min(WINNING_LENGTH, playBoard.GetBoardSize())
+}
 
-	// Check each winning combination
-	// The same player should have all places in the a combination
+func (playBoard *Board) GetWinningCombinations() [][][2]uint8 {
+	return playBoard.winningCombinations
+}
 
-	if len(winningCombinations) != ((boardSize * 2) + 2) {
-		fmt.Println("Error: winningCombinations #3 is not the same length as boardSize")
-		fmt.Println(winningCombinations)
-		return false
-	}
+func (playBoard *Board) createWinningCombinations() [][][2]uint8 {
+	// Create list of possible winning combinations
+	/*
+		On a 4x4 board:
+				[
+					[[0,0], [0,1], [0,2], [0,3]], Row #1
+					...
+		            [[0,0], [1,0], [2,0], [3,0]], Col #1
+		            ...
+		            [[0,0], [1,1], [2,2], [3,3]], Diagonal #1
+					[[0,3], [1,2], [2,1], [3,0]], Diagonal #2
+				]
+		On bigger boards every line of four places is a winning combination
+	*/
+	boardSize := playBoard.GetBoardSize()
+	winningLength := playBoard.GetWinningLength()
+	var winningCombinations [][][2]uint8
 
-	// Check each winning combination
-	// The same player should have all places in the a combination
-	for _, winningCombination := range winningCombinations {
-		countPlaces := [3]int{0, 0, 0}
-		for _, place := range winningCombination {
-			holder := playBoard.GetPosition(place[0], int(place[1]))
-			countPlaces[holder]++
-		}
-		if countPlaces[player] == boardSize {
-			return true
+	// Walk in every direction: rows, columns, forward and backward diagonals
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, direction := range directions {
+		for i := 0; i < boardSize; i++ {
+			for j := 0; j < boardSize; j++ {
+				// Check if the line fits on the board
+				endI := i + direction[0]*(winningLength-1)
+				endJ := j + direction[1]*(winningLength-1)
+				if endI < 0 || endI >= boardSize || endJ < 0 || endJ >= boardSize {
+					continue
+				}
+
+				// Create the combination
+				rowCombinations := [][2]uint8{}
+				for k := 0; k < winningLength; k++ {
+					rowCombinations = append(rowCombinations, [2]uint8{uint8(i + direction[0]*k), uint8(j + direction[1]*k)})
+				}
+				winningCombinations = append(winningCombinations, rowCombinations)
+			}
 		}
 	}
-	return false
+	return winningCombinations
 }
@@ -1,28 +1,159 @@
 package game
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/martijnwiekens/go-learning/fourinarow/board"
 	"github.com/martijnwiekens/go-learning/fourinarow/players/ai"
 	"github.com/martijnwiekens/go-learning/fourinarow/players/human"
+	"github.com/martijnwiekens/go-learning/fourinarow/profiles"
 	"github.com/martijnwiekens/go-learning/fourinarow/ui"
 )
 
-var totalGames uint8 = 0
+var totalGames int = 0
+
+// Store of the player profiles, nil when we don't keep statistics
+var profileStore *profiles.Store
+
+// NO_WINNER is returned by checkWinner when the game is not finished yet
+const NO_WINNER uint8 = 255
 
 type Player interface {
 	AskForMove(playBoard *board.Board) uint8
 }
 
+// TimedPlayer is a player that wants to know how much time is left on its clock
+type TimedPlayer interface {
+	SetRemainingTime(remainingTime time.Duration, increment time.Duration)
+}
+
+// DeadlinePlayer is a player that stops asking for the move when the clock runs out
+type DeadlinePlayer interface {
+	AskForMoveBefore(playBoard *board.Board, deadline time.Time) (uint8, bool)
+}
+
+type TimeControl struct {
+	Time      time.Duration // Time on the clock of each player at the start, 0 means no clock
+	Increment time.Duration // Time added to the clock after each move
+}
+
+var timeControl TimeControl
+
+type PlayerSettings struct {
+	Name   string // Name of the player in the statistics, defaults to "Player 1", "Player 2", ...
+	Symbol string // Character shown on the board
+	Color  string // Color of the character RED, YELLOW, GREEN, BLUE, MAGENTA, CYAN or empty
+	IsAi   bool   // Whether or not the AI plays for this player
+}
+
+var DEFAULT_PLAYERS = []PlayerSettings{
+	{Symbol: "X", Color: "RED"},
+	{Symbol: "O", Color: "YELLOW"},
+	{Symbol: "#", Color: "GREEN"},
+	{Symbol: "@", Color: "BLUE"},
+}
+
+const MIN_PLAYERS int = 2
+const MAX_PLAYERS int = 4
+
+// MAX_BOARD_SIZE keeps the numbers of the rows and columns a single character
+const MAX_BOARD_SIZE int = 9
+
 type Game struct {
 	currentPlayerIndicator uint8
 	currentPlayer          Player
-	totalTurns             uint8
-	player1                Player
-	player2                Player
+	totalTurns             int
+	players                []Player
+	participants           []profiles.Participant
+	clocks                 []time.Duration
 	playBoard              *board.Board
 }
 
+func SetTimeControl(newTimeControl TimeControl) {
+	timeControl = newTimeControl
+}
+
+func SetProfileStore(store *profiles.Store) {
+	profileStore = store
+}
+
+// GetDefaultBoardSize finds the size of the board for the number of players
+func GetDefaultBoardSize(players int) uint8 {
+	// Every player needs room for its own lines
+	return uint8(players * 2)
+}
+
+// ValidateBoardSize returns an error when the board is too small to win on or too large to show
+func ValidateBoardSize(boardSize int) error {
+	if boardSize < board.WINNING_LENGTH || boardSize > MAX_BOARD_SIZE {
+		return fmt.Errorf("the board size should be between %d and %d, got %d", board.WINNING_LENGTH, MAX_BOARD_SIZE, boardSize)
+	}
+	return nil
+}
+
+// NewPlayerSettings creates the players, the first players are human and the others are played by the AI.
+// The symbols look like "X:RED,O:YELLOW", the color is optional and players without a symbol keep the default.
+func NewPlayerSettings(players int, humans int, symbols string) ([]PlayerSettings, error) {
+	// Check the players
+	if players < MIN_PLAYERS || players > MAX_PLAYERS {
+		return nil, fmt.Errorf("the number of players should be between %d and %d, got %d", MIN_PLAYERS, MAX_PLAYERS, players)
+	}
+	if humans < 0 || humans > players {
+		return nil, fmt.Errorf("the number of humans should be between 0 and %d, got %d", players, humans)
+	}
+
+	// Create the players
+	settings := make([]PlayerSettings, players)
+	copy(settings, DEFAULT_PLAYERS)
+	for index := range settings {
+		settings[index].IsAi = index >= humans
+	}
+
+	// Set the symbols
+	if symbols == "" {
+		return settings, nil
+	}
+	parts := strings.Split(symbols, ",")
+	if len(parts) > players {
+		return nil, fmt.Errorf("got %d symbols for %d players", len(parts), players)
+	}
+	for index, part := range parts {
+		character, color, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if len([]rune(character)) != 1 {
+			return nil, fmt.Errorf("the symbol of player %d should be a single character, got %q", index+1, character)
+		}
+		color = strings.ToUpper(color)
+		if _, ok := ui.COLOR_CODES[color]; color != "" && !ok {
+			return nil, fmt.Errorf("unknown color %s for player %d", color, index+1)
+		}
+		settings[index].Symbol = character
+		settings[index].Color = color
+	}
+
+	// Every player needs its own symbol
+	var characters []string
+	for _, player := range settings {
+		if slices.Contains(characters, player.Symbol) {
+			return nil, fmt.Errorf("the symbol %s is used by more than one player", player.Symbol)
+		}
+		characters = append(characters, player.Symbol)
+	}
+	return settings, nil
+}
+
 func StartGame(boardSize uint8, withAi bool) {
+	// Create two players, the AI will be the second player
+	players := []PlayerSettings{DEFAULT_PLAYERS[0], DEFAULT_PLAYERS[1]}
+	players[1].IsAi = withAi
+
+	// Start the game
+	StartMultiplayerGame(boardSize, players)
+}
+
+func StartMultiplayerGame(boardSize uint8, playerSettings []PlayerSettings) {
 	// Print a message
 	ui.PrintIntGame()
 
@@ -31,28 +162,58 @@ func StartGame(boardSize uint8, withAi bool) {
 		currentPlayerIndicator: 1,
 		currentPlayer:          nil,
 		totalTurns:             1,
-		player1:                &human.HumanPlayer{},
-		player2:                &human.HumanPlayer{},
+		players:                []Player{},
+		clocks:                 []time.Duration{},
 		playBoard:              board.NewBoard(boardSize),
 	}
 
 	// Count the game
 	totalGames++
 
-	// Check if we need an AI
-	if withAi {
-		// Create a new AI
-		game.player2 = &ai.AIPlayer{Mode: "MIN_MAX"}
+	// Create the players
+	var symbols []ui.Symbol
+	for index, settings := range playerSettings {
+		indicator := uint8(index + 1)
+		symbols = append(symbols, ui.Symbol{Character: settings.Symbol, Color: settings.Color})
+		game.clocks = append(game.clocks, timeControl.Time)
+
+		// Find the name of the player
+		participant := profiles.Participant{Name: settings.Name}
+		if participant.Name == "" {
+			participant.Name = fmt.Sprintf("Player %d", indicator)
+		}
+
+		// Check if we need an AI
+		if settings.IsAi {
+			// The MIN_MAX AI only knows how to play as O against X, and searches until the end of the game
+			mode := "PARANOID"
+			if len(playerSettings) == 2 && indicator == ai.PLAYER_O && boardSize <= GetDefaultBoardSize(2) {
+				mode = "MIN_MAX"
+			}
+			participant.AiLevel = mode
+
+			// Create a new AI
+			game.players = append(game.players, &ai.AIPlayer{
+				Mode:      mode,
+				Indicator: indicator,
+				Players:   uint8(len(playerSettings)),
+			})
+		} else {
+			game.players = append(game.players, &human.HumanPlayer{})
+		}
+		game.participants = append(game.participants, participant)
 	}
+	ui.SetPlayerSymbols(symbols)
+	ui.ResetScreen(game.playBoard)
 
 	// Set the current player
-	game.currentPlayer = game.player1
+	game.currentPlayer = game.players[0]
 
 	// Start the game loop
 	restart := gameLoop(&game)
 	if restart {
 		ui.PrintStartGame(totalGames)
-		StartGame(boardSize, withAi)
+		StartMultiplayerGame(boardSize, playerSettings)
 	}
 }
 
@@ -66,30 +227,36 @@ func gameLoop(gameObj *Game) bool {
 	// Print the current player
 	ui.PrintTurn(gameObj.currentPlayerIndicator)
 
-	// Keep asking for the right move
-	var rightMove bool = false
-	for !rightMove {
-		// Ask for move
-		newRow := gameObj.currentPlayer.AskForMove(playBoardObj)
-
-		// Check if the move is valid
-		rightMove = checkMove(newRow, gameObj)
+	// Let the player move, until the clock runs out
+	if !playTurn(gameObj) {
+		// The player ran out of time and loses the game
+		clockIndex := gameObj.currentPlayerIndicator - 1
+		ui.PrintClocks(gameObj.clocks, gameObj.currentPlayerIndicator)
+		ui.PrintLostOnTime(gameObj.currentPlayerIndicator, gameObj.totalTurns)
+		ui.PrintBoard(playBoardObj)
 
-		// Wrong move
-		if !rightMove {
-			ui.WrongMove()
+		// With two players the other player wins
+		winnerIndex := -1
+		if len(gameObj.players) == 2 {
+			winnerIndex = 1 - int(clockIndex)
 		}
+		recordGame(gameObj, winnerIndex, int(clockIndex))
+		return ui.AskForRestart()
 	}
 
 	// Check for winner
 	winner := checkWinner(gameObj)
-	if winner != 3 {
+	if winner != NO_WINNER {
 		// Somebody won the game
+		ui.SetWinningLine(playBoardObj.GetWinningLine(winner))
 		ui.PrintWinner(winner, gameObj.totalTurns)
 
 		// Print the winning board
 		ui.PrintBoard(playBoardObj)
 
+		// Update the statistics, a tie has no winner
+		recordGame(gameObj, int(winner)-1, -1)
+
 		// Ask for restart
 		return ui.AskForRestart()
 	}
@@ -104,6 +271,94 @@ func gameLoop(gameObj *Game) bool {
 	return gameLoop(gameObj)
 }
 
+// playTurn asks the current player for the right move, returns false when the player ran out of time
+func playTurn(gameObj *Game) bool {
+	// Find the board
+	playBoardObj := gameObj.playBoard
+
+	// Start the clock
+	hasClock := timeControl.Time > 0
+	clockIndex := gameObj.currentPlayerIndicator - 1
+	var deadline time.Time
+	if hasClock {
+		ui.PrintClocks(gameObj.clocks, gameObj.currentPlayerIndicator)
+
+		// Tell the player how much time is left
+		if timedPlayer, ok := gameObj.currentPlayer.(TimedPlayer); ok {
+			timedPlayer.SetRemainingTime(gameObj.clocks[clockIndex], timeControl.Increment)
+		}
+		deadline = time.Now().Add(gameObj.clocks[clockIndex])
+	}
+	startTime := time.Now()
+
+	// Keep asking for the right move
+	var rightMove bool = false
+	for !rightMove {
+		// Ask for move, a wrong move doesn't stop the clock
+		newRow, inTime := askMoveInTime(gameObj, deadline)
+		if !inTime {
+			gameObj.clocks[clockIndex] = 0
+			return false
+		}
+
+		// Check if the move is valid
+		rightMove = checkMove(newRow, gameObj)
+
+		// Wrong move
+		if !rightMove {
+			ui.WrongMove()
+		} else {
+			// Show where the piece has landed
+			landedRow := playBoardObj.GetLandedPosition(newRow)
+			ui.PrintMove(playBoardObj, newRow, landedRow, gameObj.currentPlayerIndicator)
+		}
+	}
+
+	// Stop the clock
+	if hasClock {
+		return stopClock(gameObj, clockIndex, time.Since(startTime))
+	}
+	return true
+}
+
+// askMoveInTime asks the current player for a move, inTime is false when the clock ran out first.
+// The AI stops searching when its own time is up, a late answer is ignored.
+func askMoveInTime(gameObj *Game, deadline time.Time) (newRow uint8, inTime bool) {
+	// Without a clock the player can take all the time
+	if deadline.IsZero() {
+		return gameObj.currentPlayer.AskForMove(gameObj.playBoard), true
+	}
+
+	// A human stops reading when the clock runs out, nothing keeps reading the next input
+	if deadlinePlayer, ok := gameObj.currentPlayer.(DeadlinePlayer); ok {
+		return deadlinePlayer.AskForMoveBefore(gameObj.playBoard, deadline)
+	}
+
+	// Race the move against the clock
+	moves := make(chan uint8, 1)
+	go func() {
+		moves <- gameObj.currentPlayer.AskForMove(gameObj.playBoard)
+	}()
+	select {
+	case newRow := <-moves:
+		return newRow, true
+	case <-time.After(time.Until(deadline)):
+		return 0, false
+	}
+}
+
+// stopClock takes the time of the move from the clock, returns false when the player ran out of time.
+// The increment is only added when the player moved in time.
+func stopClock(gameObj *Game, clockIndex uint8, elapsed time.Duration) bool {
+	gameObj.clocks[clockIndex] -= elapsed
+	if gameObj.clocks[clockIndex] <= 0 {
+		gameObj.clocks[clockIndex] = 0
+		return false
+	}
+	gameObj.clocks[clockIndex] += timeControl.Increment
+	return true
+}
+
 func checkMove(newRow uint8, gameObj *Game) bool {
 	// Get the board
 	playBoardObj := gameObj.playBoard
@@ -136,15 +391,25 @@ func checkWinner(gameObj *Game) uint8 {
 		return 0
 	}
 
-	return 3
+	return NO_WINNER
 }
 
 func changePlayer(gameObj *Game) {
-	if gameObj.currentPlayerIndicator == 1 {
-		gameObj.currentPlayerIndicator = 2
-		gameObj.currentPlayer = gameObj.player2
-	} else if gameObj.currentPlayerIndicator == 2 {
-		gameObj.currentPlayerIndicator = 1
-		gameObj.currentPlayer = gameObj.player1
+	// Rotate to the next player, after the last player comes the first
+	gameObj.currentPlayerIndicator = gameObj.currentPlayerIndicator%uint8(len(gameObj.players)) + 1
+	gameObj.currentPlayer = gameObj.players[gameObj.currentPlayerIndicator-1]
+}
+
+func recordGame(gameObj *Game, winner int, loser int) {
+	// Check if we keep statistics
+	if profileStore == nil {
+		return
+	}
+
+	// Update and save the profiles
+	profileStore.RecordGame(gameObj.participants, winner, loser)
+	err := profileStore.Save()
+	if err != nil {
+		ui.PrintError("Can't save the player profiles", err)
 	}
 }
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/fourinarow/board"
+)
+
+// idlePlayer never moves, like a human that walked away from the game
+type idlePlayer struct {
+	moves chan uint8
+}
+
+func (p *idlePlayer) AskForMove(playBoard *board.Board) uint8 {
+	return <-p.moves
+}
+
+// quickPlayer moves in the first column at once and remembers the time on its clock
+type quickPlayer struct {
+	remainingTime time.Duration
+	increment     time.Duration
+}
+
+func (p *quickPlayer) AskForMove(playBoard *board.Board) uint8 {
+	return 0
+}
+
+func (p *quickPlayer) SetRemainingTime(remainingTime time.Duration, increment time.Duration) {
+	p.remainingTime = remainingTime
+	p.increment = increment
+}
+
+func prepareGame(t *testing.T, newTimeControl TimeControl, players ...Player) *Game {
+	SetTimeControl(newTimeControl)
+	t.Cleanup(func() { SetTimeControl(TimeControl{}) })
+	gameObj := &Game{
+		currentPlayerIndicator: 1,
+		currentPlayer:          players[0],
+		totalTurns:             1,
+		players:                players,
+		playBoard:              board.NewBoard(4),
+	}
+	for range players {
+		gameObj.clocks = append(gameObj.clocks, newTimeControl.Time)
+	}
+	return gameObj
+}
+
+func TestClockAddsIncrement(t *testing.T) {
+	player := &quickPlayer{}
+	gameObj := prepareGame(t, TimeControl{Time: time.Minute, Increment: 2 * time.Second}, player, &quickPlayer{})
+	if !playTurn(gameObj) {
+		t.Fatalf(`The move should be in time`)
+	}
+	if player.remainingTime != time.Minute || player.increment != 2*time.Second {
+		t.Fatalf(`The player should know its clock, got %v + %v`, player.remainingTime, player.increment)
+	}
+	if gameObj.clocks[0] <= time.Minute || gameObj.clocks[0] > time.Minute+2*time.Second {
+		t.Fatalf(`The increment should be added after the move, got %v`, gameObj.clocks[0])
+	}
+	if gameObj.clocks[1] != time.Minute {
+		t.Fatalf(`The clock of the other player should not run, got %v`, gameObj.clocks[1])
+	}
+}
+
+func TestClockRunsOut(t *testing.T) {
+	gameObj := prepareGame(t, TimeControl{Time: time.Second, Increment: time.Second}, &quickPlayer{}, &quickPlayer{})
+	if !stopClock(gameObj, 1, 500*time.Millisecond) || gameObj.clocks[1] != 1500*time.Millisecond {
+		t.Fatalf(`Half a second should be left plus the increment, got %v`, gameObj.clocks[1])
+	}
+	if stopClock(gameObj, 0, 2*time.Second) || gameObj.clocks[0] != 0 {
+		t.Fatalf(`A move that took too long should lose without an increment, got %v`, gameObj.clocks[0])
+	}
+}
+
+func TestIdlePlayerLosesOnTime(t *testing.T) {
+	player := &idlePlayer{moves: make(chan uint8)}
+	defer close(player.moves)
+	gameObj := prepareGame(t, TimeControl{Time: 20 * time.Millisecond}, player, &quickPlayer{})
+
+	// The clock ends the turn, without waiting for the move
+	startTime := time.Now()
+	if playTurn(gameObj) {
+		t.Fatalf(`A player that never moves should run out of time`)
+	}
+	if elapsed := time.Since(startTime); elapsed > time.Second {
+		t.Fatalf(`The turn should end when the clock runs out, took %v`, elapsed)
+	}
+	if gameObj.clocks[0] != 0 {
+		t.Fatalf(`The clock should be empty, got %v`, gameObj.clocks[0])
+	}
+}
+
+func TestNoClockWaitsForMove(t *testing.T) {
+	player := &idlePlayer{moves: make(chan uint8, 1)}
+	player.moves <- 2
+	gameObj := prepareGame(t, TimeControl{}, player, &quickPlayer{})
+	if !playTurn(gameObj) || gameObj.playBoard.GetLandedPosition(2) == -1 {
+		t.Fatalf(`Without a clock the move should be played`)
+	}
+}
+
+func TestNewPlayerSettings(t *testing.T) {
+	players, err := NewPlayerSettings(3, 1, "A:cyan,B")
+	if err != nil {
+		t.Fatalf(`The players should be created, got %v`, err)
+	}
+	if len(players) != 3 || players[0].IsAi || !players[1].IsAi || !players[2].IsAi {
+		t.Fatalf(`The first player should be human and the others AI, got %v`, players)
+	}
+	if players[0].Symbol != "A" || players[0].Color != "CYAN" || players[1].Symbol != "B" || players[1].Color != "" || players[2].Symbol != "#" {
+		t.Fatalf(`The symbols should be set and the rest kept, got %v`, players)
+	}
+	for _, wrong := range []struct {
+		players int
+		humans  int
+		symbols string
+	}{
+		{1, 1, ""},
+		{5, 1, ""},
+		{2, 3, ""},
+		{2, 1, "X,O,#"},
+		{2, 1, "XX"},
+		{2, 1, ",O"},
+		{2, 1, "X:PINK"},
+		{2, 1, "O"},
+	} {
+		if _, err := NewPlayerSettings(wrong.players, wrong.humans, wrong.symbols); err == nil {
+			t.Fatalf(`%d players, %d humans and symbols %q should be refused`, wrong.players, wrong.humans, wrong.symbols)
+		}
+	}
+}
+
+func TestDefaultBoardSize(t *testing.T) {
+	for players := MIN_PLAYERS; players <= MAX_PLAYERS; players++ {
+		if err := ValidateBoardSize(int(GetDefaultBoardSize(players))); err != nil {
+			t.Fatalf(`The default board of %d players should be valid, got %v`, players, err)
+		}
+	}
+	if GetDefaultBoardSize(3) <= GetDefaultBoardSize(2) {
+		t.Fatalf(`More players should get a larger board`)
+	}
+	if ValidateBoardSize(MAX_BOARD_SIZE+1) == nil || ValidateBoardSize(2) == nil {
+		t.Fatalf(`A board that is too large or too small should be refused`)
+	}
+}
@@ -2,12 +2,20 @@ package ai
 
 import (
 	"math/rand/v2"
+	"time"
 
 	"github.com/martijnwiekens/go-learning/fourinarow/board"
 )
 
 type AIPlayer struct {
-	Mode string
+	Mode      string // RANDOM, MIN_MAX or PARANOID
+	Indicator uint8  // The player number of the AI on the board
+	Players   uint8  // Total number of players in the game
+	MaxDepth  int    // How many turns the PARANOID search looks ahead
+	Workers   int    // How many goroutines search at the same time, 0 uses GOMAXPROCS
+
+	remainingTime time.Duration // Time left on the clock of the AI, 0 when there is no clock
+	increment     time.Duration // Time added to the clock after each move
 }
 
 // const AI_PLAYER_MODE string = "RANDOM"
@@ -19,12 +27,21 @@ const (
 	PLAYER_O = 2
 )
 
+const DEFAULT_MAX_DEPTH int = 4
+
 func (aiPlayer *AIPlayer) AskForMove(playBoard *board.Board) uint8 {
+	// Use the clock when we have one
+	if aiPlayer.remainingTime > 0 && (aiPlayer.Mode == "MIN_MAX" || aiPlayer.Mode == "PARANOID") {
+		return aiPlayer.getTimedMove(playBoard)
+	}
+
 	switch aiPlayer.Mode {
 	case "RANDOM":
 		return aiPlayer.getRandomMove(playBoard)
 	case "MIN_MAX":
 		return aiPlayer.getMinMaxMove(playBoard)
+	case "PARANOID":
+		return aiPlayer.getParanoidMove(playBoard)
 	default:
 		return aiPlayer.getRandomMove(playBoard)
 	}
@@ -47,21 +64,9 @@ func (aiPlayer *AIPlayer) getRandomMove(playBoard *board.Board) uint8 {
 }
 
 func (aiPlayer *AIPlayer) getMinMaxMove(playBoard *board.Board) uint8 {
-	bestScore := -1000
-	var move uint8
-	for i := 0; i < playBoard.GetBoardSize(); i++ {
-		lastSetPosition := playBoard.LastSetPosition(uint8(i))
-		if playBoard.GetPosition(uint8(i), int(lastSetPosition)) == EMPTY {
-			playBoard.SetPosition(uint8(i), int(lastSetPosition), PLAYER_O)
-			score := Minimax(playBoard, 0, false)
-			playBoard.SetPosition(uint8(i), int(lastSetPosition), EMPTY)
-			if score > bestScore {
-				bestScore = score
-				move = uint8(i)
-			}
-		}
-	}
-	return move
+	return aiPlayer.searchRootMoves(playBoard, PLAYER_O, func(b *board.Board) int {
+		return Minimax(b, 0, false)
+	})
 }
 
 func Minimax(b *board.Board, depth int, isMaximizing bool) int {
@@ -111,3 +116,77 @@ func Minimax(b *board.Board, depth int, isMaximizing bool) int {
 		return bestScore
 	}
 }
+
+func (aiPlayer *AIPlayer) getParanoidMove(playBoard *board.Board) uint8 {
+	// Find the search depth
+	maxDepth := aiPlayer.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DEFAULT_MAX_DEPTH
+	}
+
+	return aiPlayer.searchParanoid(playBoard, maxDepth, time.Time{})
+}
+
+func (aiPlayer *AIPlayer) searchParanoid(playBoard *board.Board, maxDepth int, deadline time.Time) uint8 {
+	// Find the next player after the AI
+	nextPlayer := aiPlayer.Indicator%aiPlayer.Players + 1
+
+	return aiPlayer.searchRootMoves(playBoard, aiPlayer.Indicator, func(b *board.Board) int {
+		return Paranoid(b, 0, maxDepth, aiPlayer.Indicator, nextPlayer, aiPlayer.Players, deadline)
+	})
+}
+
+// Paranoid is a minimax search for more than two players.
+// The AI assumes all other players work together against it,
+// so every other player minimizes the score of the AI.
+// The search stops early when the deadline has passed, a zero deadline means no limit.
+func Paranoid(b *board.Board, depth int, maxDepth int, aiPlayer uint8, currentPlayer uint8, totalPlayers uint8, deadline time.Time) int {
+	// Check if we are out of time
+	if !deadline.IsZero() && time.Now().After(deadline) {
+		return 0
+	}
+
+	// Check if somebody has won
+	for player := uint8(1); player <= totalPlayers; player++ {
+		if b.CheckWin(player) {
+			if player == aiPlayer {
+				return 10 - depth
+			}
+			return -10 + depth
+		}
+	}
+
+	// Check if there are places left
+	if b.IsFull() {
+		return 0
+	}
+
+	// Stop looking when we are too deep
+	if depth >= maxDepth {
+		return 0
+	}
+
+	// Find the player after this one
+	nextPlayer := currentPlayer%totalPlayers + 1
+
+	// Maximizing player
+	isMaximizing := currentPlayer == aiPlayer
+	bestScore := 1000
+	if isMaximizing {
+		bestScore = -1000
+	}
+	for i := 0; i < b.GetBoardSize(); i++ {
+		lastSetPosition := b.LastSetPosition(uint8(i))
+		if b.GetPosition(uint8(i), int(lastSetPosition)) == EMPTY {
+			b.SetPosition(uint8(i), int(lastSetPosition), currentPlayer)
+			score := Paranoid(b, depth+1, maxDepth, aiPlayer, nextPlayer, totalPlayers, deadline)
+			b.SetPosition(uint8(i), int(lastSetPosition), EMPTY)
+			if isMaximizing && score > bestScore {
+				bestScore = score
+			} else if !isMaximizing && score < bestScore {
+				bestScore = score
+			}
+		}
+	}
+	return bestScore
+}
@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/fourinarow/board"
+)
+
+func prepareBoard(size uint8, moves []uint8) *board.Board {
+	// Play the moves, the players take turns starting with X
+	playBoard := board.NewBoard(size)
+	for index, move := range moves {
+		playBoard.SetPosition(move, -1, uint8(index%2)+1)
+	}
+	return playBoard
+}
+
+func TestParallelSearchMatchesSingleWorker(t *testing.T) {
+	playBoard := prepareBoard(4, []uint8{0, 1, 1, 2})
+
+	// Search with a single goroutine
+	singleAi := &AIPlayer{Mode: "MIN_MAX", Workers: 1}
+	singleMove := singleAi.AskForMove(playBoard)
+
+	// Search with all goroutines
+	parallelAi := &AIPlayer{Mode: "MIN_MAX", Workers: 4}
+	parallelMove := parallelAi.AskForMove(playBoard)
+	if singleMove != parallelMove {
+		t.Fatalf(`Parallel search should find move %d, got %d`, singleMove, parallelMove)
+	}
+
+	// The search should not change the board
+	if playBoard.GetPosition(3, -1) != EMPTY || playBoard.GetLandedPosition(1) != 2 {
+		t.Fatalf(`Search should leave the board untouched`)
+	}
+}
+
+func TestParanoidBlocksWinningMove(t *testing.T) {
+	// X has three in a row at the bottom of a 7x7 board and can win in column 3
+	playBoard := board.NewBoard(7)
+	for i := uint8(0); i < 3; i++ {
+		playBoard.SetPosition(i, -1, PLAYER_X)
+	}
+	playBoard.SetPosition(6, -1, PLAYER_O)
+	playBoard.SetPosition(6, -1, 3)
+
+	// O plays next, the third player comes after O
+	aiPlayer := &AIPlayer{Mode: "PARANOID", Indicator: PLAYER_O, Players: 3, MaxDepth: 3}
+	move := aiPlayer.AskForMove(playBoard)
+	if move != 3 {
+		t.Fatalf(`Paranoid AI should block column 3, got %d`, move)
+	}
+}
+
+// Run the benchmarks with more CPUs to see the speed-up of the parallel search:
+// go test -run XXX -bench . -cpu 1,2,4 ./players/ai/
+func BenchmarkMinMaxMove(b *testing.B) {
+	playBoard := prepareBoard(4, []uint8{0, 1, 1, 2})
+	aiPlayer := &AIPlayer{Mode: "MIN_MAX"}
+	for i := 0; i < b.N; i++ {
+		aiPlayer.AskForMove(playBoard)
+	}
+}
+
+func BenchmarkMinMaxMoveSingleWorker(b *testing.B) {
+	playBoard := prepareBoard(4, []uint8{0, 1, 1, 2})
+	aiPlayer := &AIPlayer{Mode: "MIN_MAX", Workers: 1}
+	for i := 0; i < b.N; i++ {
+		aiPlayer.AskForMove(playBoard)
+	}
+}
+
+func BenchmarkParanoidMove(b *testing.B) {
+	playBoard := prepareBoard(7, []uint8{3, 3, 2})
+	aiPlayer := &AIPlayer{Mode: "PARANOID", Indicator: 1, Players: 3, MaxDepth: 4}
+	for i := 0; i < b.N; i++ {
+		aiPlayer.AskForMove(playBoard)
+	}
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/martijnwiekens/go-learning/fourinarow/board"
+)
+
+// searchRootMoves tries every possible move for the player and scores it with the search.
+// The moves are split over the workers, each worker plays on its own copy of the board.
+// It returns the first move with the best score, just like a search on a single goroutine.
+func (aiPlayer *AIPlayer) searchRootMoves(playBoard *board.Board, player uint8, search func(b *board.Board) int) uint8 {
+	// Find all possible moves
+	var moves []uint8
+	for i := 0; i < playBoard.GetBoardSize(); i++ {
+		if playBoard.GetPosition(uint8(i), -1) == EMPTY {
+			moves = append(moves, uint8(i))
+		}
+	}
+
+	// Find how many workers we need
+	workers := aiPlayer.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(moves))
+
+	// Every worker takes the next move from the channel
+	scores := make([]int, len(moves))
+	nextMoves := make(chan int, len(moves))
+	for index := range moves {
+		nextMoves <- index
+	}
+	close(nextMoves)
+
+	// Start the workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workerBoard := playBoard.Copy()
+			for index := range nextMoves {
+				row := moves[index]
+				col := int(workerBoard.LastSetPosition(row))
+				workerBoard.SetPosition(row, col, player)
+				scores[index] = search(workerBoard)
+				workerBoard.SetPosition(row, col, EMPTY)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Find the best move
+	bestScore := -1000
+	var move uint8
+	for index, score := range scores {
+		if score > bestScore {
+			bestScore = score
+			move = moves[index]
+		}
+	}
+	return move
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"time"
+
+	"github.com/martijnwiekens/go-learning/fourinarow/board"
+)
+
+// Never use more than this part of the remaining time on a single move
+const MAX_TIME_PART = 4
+
+func (aiPlayer *AIPlayer) SetRemainingTime(remainingTime time.Duration, increment time.Duration) {
+	aiPlayer.remainingTime = remainingTime
+	aiPlayer.increment = increment
+}
+
+func (aiPlayer *AIPlayer) getTimedMove(playBoard *board.Board) uint8 {
+	// The MIN_MAX AI plays as O against X
+	if aiPlayer.Players == 0 {
+		aiPlayer.Indicator = PLAYER_O
+		aiPlayer.Players = 2
+	}
+
+	// Find until when we can search
+	deadline := time.Now().Add(aiPlayer.getTimeBudget(playBoard))
+
+	// Always have a move ready
+	move := aiPlayer.getRandomMove(playBoard)
+
+	// Look deeper every time, until we run out of time
+	emptyPlaces := countEmptyPlaces(playBoard)
+	for depth := 1; depth <= emptyPlaces; depth++ {
+		newMove := aiPlayer.searchParanoid(playBoard, depth, deadline)
+
+		// The search was not finished, so we can't trust the move
+		if time.Now().After(deadline) {
+			break
+		}
+		move = newMove
+	}
+	return move
+}
+
+func (aiPlayer *AIPlayer) getTimeBudget(playBoard *board.Board) time.Duration {
+	// Spread the remaining time over the moves we still have to make
+	movesLeft := max(1, countEmptyPlaces(playBoard)/int(aiPlayer.Players))
+	budget := aiPlayer.remainingTime/time.Duration(movesLeft) + aiPlayer.increment
+
+	// Keep some time for later
+	return min(budget, aiPlayer.remainingTime/MAX_TIME_PART)
+}
+
+func countEmptyPlaces(playBoard *board.Board) int {
+	emptyPlaces := 0
+	for _, row := range playBoard.GetBoard() {
+		for _, place := range row {
+			if place == EMPTY {
+				emptyPlaces++
+			}
+		}
+	}
+	return emptyPlaces
+}
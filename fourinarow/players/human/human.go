@@ -1,6 +1,8 @@
 package human
 
 import (
+	"time"
+
 	"github.com/martijnwiekens/go-learning/fourinarow/board"
 	"github.com/martijnwiekens/go-learning/fourinarow/ui"
 )
@@ -9,5 +11,15 @@ type HumanPlayer struct {
 }
 
 func (humanPlayer *HumanPlayer) AskForMove(playBoard *board.Board) uint8 {
-	return ui.AskMove()
+	row, _ := humanPlayer.AskForMoveBefore(playBoard, time.Time{})
+	return row
+}
+
+// AskForMoveBefore stops asking when the deadline passed, so the question never outlives the turn
+func (humanPlayer *HumanPlayer) AskForMoveBefore(playBoard *board.Board, deadline time.Time) (uint8, bool) {
+	// Use the cursor in full screen mode
+	if ui.IsFullScreen() {
+		return ui.AskMoveWithCursor(playBoard, deadline)
+	}
+	return ui.AskMove(deadline)
 }
@@ -0,0 +1,108 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "profiles.json"))
+	if err != nil || len(store.Profiles) != 0 {
+		t.Fatalf(`A missing file should give an empty store, got %v %v`, store, err)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	os.WriteFile(path, []byte(`{"profiles": [`), 0644)
+	if _, err := Load(path); err == nil {
+		t.Fatalf(`An invalid file should not load`)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	store, _ := Load(path)
+	store.RecordGame([]Participant{{Name: "Alice"}, {Name: "Bob"}}, 0, -1)
+	if err := store.Save(); err != nil {
+		t.Fatalf(`The store should be saved, got %v`, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf(`The temporary file should be gone`)
+	}
+
+	// The profiles are the same after loading them again
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf(`The saved file should load, got %v`, err)
+	}
+	alice := loaded.Profiles["Alice"]
+	if alice == nil || alice.Total.Wins != 1 || alice.Opponents["Bob"].Wins != 1 {
+		t.Fatalf(`Alice should have won against Bob, got %+v`, alice)
+	}
+
+	// Profiles that were saved without results can be updated
+	loaded.Profiles["Carol"] = &Profile{Name: "Carol"}
+	loaded.Save()
+	loaded, _ = Load(path)
+	loaded.RecordGame([]Participant{{Name: "Carol"}, {AiLevel: "MIN_MAX"}}, 0, -1)
+	if loaded.Profiles["Carol"].AiLevels["MIN_MAX"].Wins != 1 {
+		t.Fatalf(`Carol should have won against the AI, got %+v`, loaded.Profiles["Carol"])
+	}
+}
+
+func TestRecordGame(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "profiles.json"))
+	players := []Participant{{Name: "Alice"}, {Name: "Bob"}, {AiLevel: "PARANOID"}}
+
+	// Alice wins twice, then Bob runs out of time in a game without a winner, then a draw
+	store.RecordGame(players, 0, -1)
+	store.RecordGame(players, 0, -1)
+	store.RecordGame(players, -1, 1)
+	store.RecordGame(players, -1, -1)
+
+	alice, bob := store.Profiles["Alice"], store.Profiles["Bob"]
+	if alice.GamesPlayed != 4 || alice.Total != (Record{Wins: 2, Draws: 2}) {
+		t.Fatalf(`Alice should have 2 wins and 2 draws, got %+v`, alice.Total)
+	}
+	if bob.Total != (Record{Losses: 3, Draws: 1}) || *bob.Opponents["Alice"] != (Record{Losses: 3, Draws: 1}) {
+		t.Fatalf(`Bob should have 3 losses and a draw, got %+v`, bob.Total)
+	}
+	if *alice.AiLevels["PARANOID"] != (Record{Wins: 2, Draws: 2}) {
+		t.Fatalf(`The results against the AI should be kept per mode, got %+v`, alice.AiLevels)
+	}
+	if _, ok := store.Profiles[""]; ok || len(store.Profiles) != 2 {
+		t.Fatalf(`The AI should have no profile, got %v`, store.Profiles)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	profile := &Profile{}
+	for _, result := range []string{RESULT_WIN, RESULT_WIN, RESULT_WIN, RESULT_LOSS, RESULT_LOSS} {
+		profile.updateStreak(result)
+	}
+	if profile.CurrentStreak != -2 || profile.LongestWinStreak != 3 {
+		t.Fatalf(`Two losses after three wins should be a streak of -2, got %d and %d`, profile.CurrentStreak, profile.LongestWinStreak)
+	}
+	profile.updateStreak(RESULT_DRAW)
+	if profile.CurrentStreak != 0 {
+		t.Fatalf(`A draw should end the streak, got %d`, profile.CurrentStreak)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "profiles.json"))
+	store.Profiles["Bob"] = &Profile{Name: "Bob", GamesPlayed: 4, Total: Record{Wins: 2, Losses: 2}}
+	store.Profiles["Alice"] = &Profile{Name: "Alice", GamesPlayed: 2, Total: Record{Wins: 2}}
+	store.Profiles["Carol"] = &Profile{Name: "Carol", GamesPlayed: 2, Total: Record{Wins: 2}}
+	store.Profiles["Dave"] = &Profile{Name: "Dave"}
+
+	var names []string
+	for _, profile := range store.GetLeaderboard() {
+		names = append(names, profile.Name)
+	}
+	if len(names) != 4 || names[0] != "Alice" || names[1] != "Carol" || names[2] != "Bob" || names[3] != "Dave" {
+		t.Fatalf(`Most wins, then the win rate, then the name should be first, got %v`, names)
+	}
+}
@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/fourinarow/board"
+)
+
+const (
+	CLEAR_SCREEN     = "\033[H\033[2J"
+	HIDE_CURSOR      = "\033[?25l"
+	SHOW_CURSOR      = "\033[?25h"
+	ALTERNATE_SCREEN = "\033[?1049h"
+	NORMAL_SCREEN    = "\033[?1049l"
+	HIGHLIGHT_LAST   = "\033[7m"
+	HIGHLIGHT_WIN    = "\033[42m"
+)
+
+const FALL_SPEED = 60 * time.Millisecond
+
+type fallingPiece struct {
+	column uint8 // Column the piece is dropped in
+	row    int   // Row the piece is currently shown at
+	target int   // Row the piece will land on
+	player uint8 // Player that dropped the piece
+}
+
+type screenState struct {
+	enabled     bool          // Whether or not the full screen mode is on
+	sttyState   string        // Terminal settings before we switched to raw mode
+	playBoard   *board.Board  // Board that is shown on the screen
+	cursor      uint8         // Column the cursor is on
+	turn        uint8         // Player that is on turn
+	lastMove    [2]int        // Column and row of the last move, -1 when there is none
+	winningLine [][2]uint8    // Places of the winning combination
+	falling     *fallingPiece // Piece that is falling down the board
+	title       string        // Line on top of the screen
+	status      string        // Line below the board
+	clocks      string        // Time left on the clock of every player
+	message     string        // Extra message, like a wrong move
+	help        string        // Keys the player can use
+}
+
+var screen = screenState{lastMove: [2]int{-1, -1}}
+
+func EnableFullScreen() error {
+	// Remember the terminal settings
+	state, err := runStty("-g")
+	if err != nil {
+		return err
+	}
+	screen.sttyState = strings.TrimSpace(state)
+
+	// Switch to raw mode, so we get every key press
+	_, err = runStty("raw", "-echo")
+	if err != nil {
+		return err
+	}
+	screen.enabled = true
+	fmt.Print(ALTERNATE_SCREEN + HIDE_CURSOR)
+	return nil
+}
+
+func DisableFullScreen() {
+	if !screen.enabled {
+		return
+	}
+
+	// Give the terminal back
+	fmt.Print(SHOW_CURSOR + NORMAL_SCREEN)
+	runStty(screen.sttyState)
+	screen.enabled = false
+}
+
+func IsFullScreen() bool {
+	return screen.enabled
+}
+
+func ResetScreen(playBoard *board.Board) {
+	screen.playBoard = playBoard
+	screen.cursor = 0
+	screen.lastMove = [2]int{-1, -1}
+	screen.winningLine = nil
+	screen.falling = nil
+	screen.status = ""
+	screen.clocks = ""
+	screen.help = ""
+}
+
+func SetWinningLine(line [][2]uint8) {
+	screen.winningLine = line
+}
+
+func PrintMove(playBoard *board.Board, column uint8, row int, player uint8) {
+	// Remember the move
+	screen.lastMove = [2]int{int(column), row}
+	if !screen.enabled {
+		return
+	}
+
+	// Let the piece fall down
+	screen.playBoard = playBoard
+	screen.falling = &fallingPiece{column: column, target: row, player: player}
+	for i := 0; i <= row; i++ {
+		screen.falling.row = i
+		renderScreen()
+		time.Sleep(FALL_SPEED)
+	}
+	screen.falling = nil
+	renderScreen()
+}
+
+// AskMoveWithCursor waits for the player to drop a piece, ok is false when the deadline passed first
+func AskMoveWithCursor(playBoard *board.Board, deadline time.Time) (column uint8, ok bool) {
+	// Show the board with the cursor
+	screen.playBoard = playBoard
+	screen.help = "LEFT/RIGHT move   ENTER drop   Q quit"
+	renderScreen()
+
+	// Wait for the player to drop a piece
+	for {
+		switch readKey(deadline) {
+		case "LEFT":
+			if screen.cursor > 0 {
+				screen.cursor--
+			}
+		case "RIGHT":
+			if int(screen.cursor) < playBoard.GetBoardSize()-1 {
+				screen.cursor++
+			}
+		case "ENTER":
+			screen.message = ""
+			screen.help = ""
+			return screen.cursor, true
+		case "TIMEOUT":
+			screen.help = ""
+			return 0, false
+		case "QUIT":
+			quitGame()
+		}
+		renderScreen()
+	}
+}
+
+func waitForEnter() {
+	for {
+		switch readKey(time.Time{}) {
+		case "ENTER":
+			return
+		case "QUIT":
+			quitGame()
+		}
+	}
+}
+
+func quitGame() {
+	DisableFullScreen()
+	os.Exit(0)
+}
+
+func readKey(deadline time.Time) string {
+	// Read a single key press, arrows are sent as 3 bytes
+	if pendingInput == "" {
+		err := readInput(deadline)
+		if err == errTimeout {
+			return "TIMEOUT"
+		} else if err != nil {
+			return "QUIT"
+		}
+	}
+	buf := []byte(pendingInput)
+	n := len(buf)
+	pendingInput = ""
+	if n == 0 {
+		return ""
+	}
+	if n == 3 && buf[0] == 27 && buf[1] == '[' {
+		switch buf[2] {
+		case 'A':
+			return "UP"
+		case 'B':
+			return "DOWN"
+		case 'C':
+			return "RIGHT"
+		case 'D':
+			return "LEFT"
+		}
+		return ""
+	}
+	switch buf[0] {
+	case '\r', '\n', ' ':
+		return "ENTER"
+	case 'q', 'Q', 3:
+		return "QUIT"
+	case 'w', 'W':
+		return "UP"
+	case 's', 'S':
+		return "DOWN"
+	case 'd', 'D':
+		return "RIGHT"
+	case 'a', 'A':
+		return "LEFT"
+	}
+	return ""
+}
+
+func renderScreen() {
+	var output []string
+	output = append(output, screen.title, "")
+
+	// Check if we have a board
+	if screen.playBoard != nil {
+		playBoard := screen.playBoard.GetBoard()
+		boardSize := len(playBoard)
+
+		// Show the piece of the current player above the cursor
+		cursorLine := ""
+		for i := 0; i < boardSize; i++ {
+			if uint8(i) == screen.cursor && screen.help != "" {
+				cursorLine += "   " + GetPlayerSymbol(screen.turn) + "  "
+			} else {
+				cursorLine += "      "
+			}
+		}
+		output = append(output, cursorLine)
+
+		// Print board
+		for i := 0; i < boardSize; i++ {
+			line := "|"
+			for j := 0; j < boardSize; j++ {
+				line += " " + getCell(uint8(j), i) + " |"
+			}
+			output = append(output, line)
+		}
+
+		// Create col header line
+		output = append(output, strings.Repeat("|_____", boardSize)+"|")
+		colLine := ""
+		for i := 0; i < boardSize; i++ {
+			colLine += fmt.Sprintf("|  %d  ", i)
+		}
+		output = append(output, colLine+"|")
+	}
+
+	// Add the text below the board
+	output = append(output, "", screen.status, screen.clocks, screen.message, "", screen.help)
+
+	// Raw mode needs a carriage return on every line
+	fmt.Print(CLEAR_SCREEN + strings.Join(output, "\r\n"))
+}
+
+func getCell(column uint8, row int) string {
+	// Find the player on this place
+	player := screen.playBoard.GetBoard()[column][row]
+	if screen.falling != nil && screen.falling.column == column {
+		if row == screen.falling.row {
+			player = screen.falling.player
+		} else if row == screen.falling.target {
+			player = 0
+		}
+	}
+
+	// Find the highlight
+	highlight := ""
+	if screen.falling == nil && screen.lastMove[0] == int(column) && screen.lastMove[1] == row {
+		highlight = HIGHLIGHT_LAST
+	}
+	for _, place := range screen.winningLine {
+		if place[0] == column && int(place[1]) == row {
+			highlight = HIGHLIGHT_WIN
+		}
+	}
+	if highlight == "" {
+		return " " + GetPlayerSymbol(player) + " "
+	}
+	return highlight + " " + GetPlayerSymbol(player) + highlight + " " + COLOR_RESET
+}
+
+func runStty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
@@ -2,11 +2,58 @@ package ui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/martijnwiekens/go-learning/fourinarow/board"
+	"github.com/martijnwiekens/go-learning/fourinarow/profiles"
 )
 
+type Symbol struct {
+	Character string // Character shown on the board, like X or O
+	Color     string // Color of the character RED, YELLOW, GREEN, BLUE, MAGENTA, CYAN or empty
+}
+
+var COLOR_CODES = map[string]string{
+	"RED":     "\033[31m",
+	"GREEN":   "\033[32m",
+	"YELLOW":  "\033[33m",
+	"BLUE":    "\033[34m",
+	"MAGENTA": "\033[35m",
+	"CYAN":    "\033[36m",
+}
+
+const COLOR_RESET = "\033[0m"
+
+var playerSymbols = []Symbol{{Character: "X"}, {Character: "O"}}
+
+func SetPlayerSymbols(symbols []Symbol) {
+	playerSymbols = symbols
+}
+
+func GetPlayerSymbol(player uint8) string {
+	// Check if we know the player
+	if player == 0 || int(player) > len(playerSymbols) {
+		return " "
+	}
+
+	// Color the character
+	symbol := playerSymbols[player-1]
+	colorCode, ok := COLOR_CODES[symbol.Color]
+	if !ok {
+		return symbol.Character
+	}
+	return colorCode + symbol.Character + COLOR_RESET
+}
+
 func PrintBoard(playBoardObj *board.Board) {
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.playBoard = playBoardObj
+		renderScreen()
+		return
+	}
+
 	var playBoard = playBoardObj.GetBoard()
 
 	// Print board
@@ -15,69 +62,197 @@ func PrintBoard(playBoardObj *board.Board) {
 		// Create row header line
 		fmt.Print("|  ")
 		for j := 0; j < len(playBoard[i]); j++ {
-			if playBoard[j][i] == 0 {
-				fmt.Print(" ")
-			} else if playBoard[j][i] == 1 {
-				fmt.Print("X")
-			} else if playBoard[j][i] == 2 {
-				fmt.Print("O")
-			}
+			fmt.Print(GetPlayerSymbol(playBoard[j][i]))
 			fmt.Print("  |  ")
 		}
 		fmt.Println()
 	}
 
 	// Create col header line
-	fmt.Println("|_____|_____|_____|_____")
+	fmt.Println(strings.Repeat("|_____", len(playBoard)))
 	for i := 0; i < len(playBoard); i++ {
 		fmt.Print("|  ", i, "  ")
 	}
 	fmt.Println()
 }
 
-func PrintWinner(winner uint8, totalTurns uint8) {
+func PrintWinner(winner uint8, totalTurns int) {
+	// Create the message
+	var message string
+	if winner == 0 {
+		message = fmt.Sprintf("Tie in %d turns!", totalTurns)
+	} else {
+		message = fmt.Sprintf("%s wins in %d turns!", GetPlayerSymbol(winner), totalTurns)
+	}
+
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.status = message
+		screen.message = ""
+		return
+	}
 	fmt.Println()
-	if winner == 1 {
-		fmt.Printf("X wins in %d turns!\n", totalTurns)
-	} else if winner == 2 {
-		fmt.Printf("O wins in %d turns!\n", totalTurns)
-	} else if winner == 3 {
-		fmt.Printf("Tie in %d turns!\n", totalTurns)
+	fmt.Println(message)
+}
+
+func PrintLostOnTime(player uint8, totalTurns int) {
+	// Create the message
+	message := fmt.Sprintf("%s ran out of time and loses in %d turns!", GetPlayerSymbol(player), totalTurns)
+
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.status = message
+		screen.message = ""
+		return
+	}
+	fmt.Println()
+	fmt.Println(message)
+}
+
+func PrintClocks(clocks []time.Duration, turn uint8) {
+	// Show the time of each player, the current player is marked with a *
+	var parts []string
+	for index, clock := range clocks {
+		marker := " "
+		if uint8(index+1) == turn {
+			marker = "*"
+		}
+		parts = append(parts, fmt.Sprintf("%s%s %s", marker, GetPlayerSymbol(uint8(index+1)), FormatClock(clock)))
+	}
+	line := strings.Join(parts, "   ")
+
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.clocks = line
+		renderScreen()
+		return
 	}
+	fmt.Println(line)
+}
+
+func FormatClock(clock time.Duration) string {
+	// Show minutes, seconds and tenths of a second
+	tenths := int(clock / (100 * time.Millisecond))
+	return fmt.Sprintf("%d:%02d.%d", tenths/600, (tenths/10)%60, tenths%10)
 }
 
 func PrintTurn(turn uint8) {
-	fmt.Println()
-	if turn == 1 {
-		fmt.Println("X's turn")
-	} else if turn == 2 {
-		fmt.Println("O's turn")
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.turn = turn
+		screen.status = fmt.Sprintf("%s's turn", GetPlayerSymbol(turn))
+		renderScreen()
+		return
 	}
+	fmt.Println()
+	fmt.Printf("%s's turn\n", GetPlayerSymbol(turn))
 }
 
-func AskMove() uint8 {
-	var row uint8
+// AskMove asks for the row, ok is false when the deadline passed first
+func AskMove(deadline time.Time) (row uint8, ok bool) {
 	fmt.Print("Enter row: ")
-	fmt.Scan(&row)
+	row, err := readNumber(deadline)
+	if err == errTimeout {
+		fmt.Println()
+		return 0, false
+	}
 	fmt.Println("_____________________")
-	return row
+	return row, true
 }
 
 func WrongMove() {
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.message = "Wrong move! Try again"
+		renderScreen()
+		return
+	}
 	fmt.Println("Wrong move! Try again")
 }
 
 func AskForRestart() bool {
 	// Ask for press ENTER to restart
+	if screen.enabled {
+		screen.help = "ENTER restart   Q quit"
+		renderScreen()
+		waitForEnter()
+		return true
+	}
 	fmt.Println("Press ENTER to restart")
-	fmt.Scanln()
+	readLine()
 	return true
 }
 
 func PrintIntGame() {
-	fmt.Println("---- TicTactToe ----")
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.title = "---- Four In A Row ----"
+		return
+	}
+	fmt.Println("---- Four In A Row ----")
 }
 
-func PrintStartGame(totalGames uint8) {
+func PrintStartGame(totalGames int) {
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.message = fmt.Sprintf("Starting new game #%d", totalGames)
+		return
+	}
 	fmt.Printf("Starting new game #%d\n", totalGames)
 }
+
+func PrintError(message string, err error) {
+	// Check if we draw the full screen
+	if screen.enabled {
+		screen.message = fmt.Sprint(message, ": ", err)
+		renderScreen()
+		return
+	}
+	fmt.Println(message+":", err)
+}
+
+func PrintLeaderboard(leaderboard []*profiles.Profile) {
+	fmt.Println("---- Leaderboard ----")
+	if len(leaderboard) == 0 {
+		fmt.Println("No games played yet")
+		return
+	}
+
+	// Print the table
+	fmt.Printf("%-3s %-16s %6s %5s %7s %6s %8s %7s\n", "#", "Name", "Played", "Wins", "Losses", "Draws", "Win rate", "Streak")
+	for index, profile := range leaderboard {
+		fmt.Printf(
+			"%-3d %-16s %6d %5d %7d %6d %7.0f%% %7s\n",
+			index+1,
+			profile.Name,
+			profile.GamesPlayed,
+			profile.Total.Wins,
+			profile.Total.Losses,
+			profile.Total.Draws,
+			profile.GetWinRate()*100,
+			formatStreak(profile.CurrentStreak),
+		)
+	}
+
+	// Print the details of every player
+	for _, profile := range leaderboard {
+		fmt.Println()
+		fmt.Printf("%s, longest win streak: %d\n", profile.Name, profile.LongestWinStreak)
+		for name, record := range profile.Opponents {
+			fmt.Printf("  vs %-16s %d wins, %d losses, %d draws\n", name, record.Wins, record.Losses, record.Draws)
+		}
+		for level, record := range profile.AiLevels {
+			fmt.Printf("  vs AI %-13s %d wins, %d losses, %d draws\n", level, record.Wins, record.Losses, record.Draws)
+		}
+	}
+}
+
+func formatStreak(streak int) string {
+	// Show wins as W3 and losses as L2
+	if streak > 0 {
+		return fmt.Sprintf("W%d", streak)
+	} else if streak < 0 {
+		return fmt.Sprintf("L%d", -streak)
+	}
+	return "-"
+}
@@ -2,85 +2,33 @@ package collisonwarning
 
 import (
 	"log"
-
-	"github.com/martijnwiekens/go-learning/gointersection/intersection"
-	"github.com/martijnwiekens/go-learning/gointersection/road"
 )
 
-func CollisionWarningOnGreen(in *intersection.Intersection, roadName string, laneName string) bool {
-	// Create a list of lanes to check
-	var checkLanes []*road.Lane
-
-	// Find all the roads
-	roadOpposite := in.GetRoadByName(GetOppositeDirection(roadName))
-	roadLeft := in.GetRoadByName(GetDirectionLeft(roadName))
-	roadRight := in.GetRoadByName(GetDirectionRight(roadName))
+// LightStates is the part of the intersection the collision warning needs
+type LightStates interface {
+	HasLane(roadName string, direction string) bool
+	GetLaneState(roadName string, direction string) string
+}
 
-	// Check if we enable all lanes
+func CollisionWarningOnGreen(in LightStates, roadName string, laneName string) bool {
+	// Turning ALL GREEN also turns LEFT, FORWARD and RIGHT GREEN
+	movements := []Movement{{Road: roadName, Lane: laneName}}
 	if laneName == "ALL" {
-		// Nothing else should be green
-		var checkRoads []*road.Road
-		checkRoads = append(checkRoads, in.GetRoadByName(GetOppositeDirection(roadName)))
-		checkRoads = append(checkRoads, in.GetRoadByName(GetDirectionLeft(roadName)))
-		checkRoads = append(checkRoads, in.GetRoadByName(GetDirectionRight(roadName)))
-		for _, road := range checkRoads {
-			if road != nil {
-				checkLanes = append(checkLanes, road.GetLanes()...)
-			}
-		}
-
-		// Check if direction is LEFT
-	} else if laneName == "LEFT" {
-		if roadOpposite != nil {
-			// FORWARD, RIGHT, but not LEFT
-			checkLanes = append(checkLanes, roadOpposite.GetLanesByName("FORWARD")...)
-			checkLanes = append(checkLanes, roadOpposite.GetLanesByName("RIGHT")...)
-		}
-		if roadLeft != nil {
-			// FORWARD, LEFT roads, but not RIGHT
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("FORWARD")...)
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("LEFT")...)
-		}
-		if roadRight != nil {
-			// FORWARD, LEFT roads, but not RIGHT
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("FORWARD")...)
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("LEFT")...)
-		}
-
-		// Check if direction is RIGHT
-	} else if laneName == "RIGHT" {
-		if roadOpposite != nil {
-			// LEFT, but not FORWARD, RIGHT
-			checkLanes = append(checkLanes, roadOpposite.GetLanesByName("LEFT")...)
-		}
-		if roadLeft != nil {
-			// FORWARD roads, but not LEFT, RIGHT
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("FORWARD")...)
-		}
-
-		// Check if direction is FORWARD
-	} else if laneName == "FORWARD" {
-		if roadOpposite != nil {
-			// RIGHT, but not FORWARD, LEFT
-			checkLanes = append(checkLanes, roadOpposite.GetLanesByName("LEFT")...)
-		}
-		if roadLeft != nil {
-			// FORWARD, LEFT roads, but not RIGHT
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("FORWARD")...)
-			checkLanes = append(checkLanes, roadLeft.GetLanesByName("LEFT")...)
-		}
-		if roadRight != nil {
-			// FORWARD, LEFT roads, but not RIGHT
-			checkLanes = append(checkLanes, roadRight.GetLanesByName("FORWARD")...)
-			checkLanes = append(checkLanes, roadRight.GetLanesByName("LEFT")...)
-		}
+		movements = append(movements, Movement{Road: roadName, Lane: "LEFT"}, Movement{Road: roadName, Lane: "FORWARD"}, Movement{Road: roadName, Lane: "RIGHT"})
 	}
 
-	// Check all the lanes
-	for _, lane := range checkLanes {
-		if lane.GetState() == "GREEN" || lane.GetState() == "ORANGE" {
-			log.Default().Println(lane.GetRoad(), ":", lane.GetDirection(), "is still GREEN! COLLISION!")
-			return true
+	// Check all the lanes that cross or merge
+	for _, movement := range movements {
+		for _, conflict := range CONFLICT_MATRIX[movement] {
+			if !in.HasLane(conflict.Road, conflict.Lane) {
+				continue
+			}
+			state := in.GetLaneState(conflict.Road, conflict.Lane)
+			// RED-ORANGE turns GREEN soon
+			if state == "GREEN" || state == "ORANGE" || state == "RED-ORANGE" {
+				log.Default().Println(conflict.Road, ":", conflict.Lane, "is still GREEN! COLLISION!")
+				return true
+			}
 		}
 	}
 	return false
@@ -1,8 +1,9 @@
-package collisonwarning
+package collisonwarning_test
 
 import (
 	"testing"
 
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
 	"github.com/martijnwiekens/go-learning/gointersection/intersection"
 	"github.com/martijnwiekens/go-learning/gointersection/road"
 )
@@ -34,19 +35,19 @@ func prepareIntersection() *intersection.Intersection {
 
 func TestGetOppositeDirection(t *testing.T) {
 	var result string
-	result = GetOppositeDirection("NORTH")
+	result = collisonwarning.GetOppositeDirection("NORTH")
 	if result != "SOUTH" {
 		t.Fatalf(`North direction should be SOUTH, got %s`, result)
 	}
-	result = GetOppositeDirection("SOUTH")
+	result = collisonwarning.GetOppositeDirection("SOUTH")
 	if result != "NORTH" {
 		t.Fatalf(`South direction should be NORTH, got %s`, result)
 	}
-	result = GetOppositeDirection("EAST")
+	result = collisonwarning.GetOppositeDirection("EAST")
 	if result != "WEST" {
 		t.Fatalf(`East direction should be WEST, got %s`, result)
 	}
-	result = GetOppositeDirection("WEST")
+	result = collisonwarning.GetOppositeDirection("WEST")
 	if result != "EAST" {
 		t.Fatalf(`West direction should be EAST, got %s`, result)
 	}
@@ -54,19 +55,19 @@ func TestGetOppositeDirection(t *testing.T) {
 
 func TestGetDirectionLeft(t *testing.T) {
 	var result string
-	result = GetDirectionLeft("NORTH")
+	result = collisonwarning.GetDirectionLeft("NORTH")
 	if result != "WEST" {
 		t.Fatalf(`North direction should be WEST, got %s`, result)
 	}
-	result = GetDirectionLeft("SOUTH")
+	result = collisonwarning.GetDirectionLeft("SOUTH")
 	if result != "EAST" {
 		t.Fatalf(`South direction should be EAST, got %s`, result)
 	}
-	result = GetDirectionLeft("EAST")
+	result = collisonwarning.GetDirectionLeft("EAST")
 	if result != "NORTH" {
 		t.Fatalf(`East direction should be NORTH, got %s`, result)
 	}
-	result = GetDirectionLeft("WEST")
+	result = collisonwarning.GetDirectionLeft("WEST")
 	if result != "SOUTH" {
 		t.Fatalf(`West direction should be SOUTH, got %s`, result)
 	}
@@ -74,19 +75,19 @@ func TestGetDirectionLeft(t *testing.T) {
 
 func TestGetDirectionRight(t *testing.T) {
 	var result string
-	result = GetDirectionRight("NORTH")
+	result = collisonwarning.GetDirectionRight("NORTH")
 	if result != "EAST" {
 		t.Fatalf(`North direction should be EAST, got %s`, result)
 	}
-	result = GetDirectionRight("SOUTH")
+	result = collisonwarning.GetDirectionRight("SOUTH")
 	if result != "WEST" {
 		t.Fatalf(`South direction should be WEST, got %s`, result)
 	}
-	result = GetDirectionRight("EAST")
+	result = collisonwarning.GetDirectionRight("EAST")
 	if result != "SOUTH" {
 		t.Fatalf(`East direction should be SOUTH, got %s`, result)
 	}
-	result = GetDirectionRight("WEST")
+	result = collisonwarning.GetDirectionRight("WEST")
 	if result != "NORTH" {
 		t.Fatalf(`West direction should be NORTH, got %s`, result)
 	}
@@ -101,14 +102,14 @@ func TestCollisionWarningOnGreenNorthAll(t *testing.T) {
 
 	// Set NORTH ALL road to GREEN, this should not be a problem
 	var result bool
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be false, got %v`, result)
 	}
 
 	// Test if we can set NORTH ALL to GREEN, when SOUTH LEFT doesn't exist
 	in.EnableLights("SOUTH", "LEFT")
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -116,7 +117,7 @@ func TestCollisionWarningOnGreenNorthAll(t *testing.T) {
 
 	// Test if we can set NORTH ALL to GREEN, when EAST LEFT
 	in.EnableLights("EAST", "LEFT")
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == false {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -124,7 +125,7 @@ func TestCollisionWarningOnGreenNorthAll(t *testing.T) {
 
 	// Test if we can set NORTH ALL to GREEN, when SOUTH ALL
 	in.EnableLights("SOUTH", "ALL")
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == false {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -132,7 +133,7 @@ func TestCollisionWarningOnGreenNorthAll(t *testing.T) {
 
 	// Test if we can set NORTH ALL to GREEN, when EAST RIGHT
 	in.EnableLights("EAST", "LEFT")
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == false {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -140,7 +141,7 @@ func TestCollisionWarningOnGreenNorthAll(t *testing.T) {
 
 	// Test if we can set NORTH ALL to GREEN, when SOUTH FORWARD
 	in.EnableLights("WEST", "FORWARD")
-	result = CollisionWarningOnGreen(in, "NORTH", "ALL")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "ALL")
 	if result == false {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -156,14 +157,14 @@ func TestCollisionWarningOnGreenNorthLeft(t *testing.T) {
 
 	// Set NORTH LEFT road to GREEN, this should not be a problem
 	var result bool
-	result = CollisionWarningOnGreen(in, "NORTH", "LEFT")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "LEFT")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be false, got %v`, result)
 	}
 
 	// Test if we can set NORTH LEFT to GREEN, when SOUTH LEFT doesn't exist
 	in.EnableLights("SOUTH", "LEFT")
-	result = CollisionWarningOnGreen(in, "NORTH", "LEFT")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "LEFT")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -179,14 +180,14 @@ func TestCollisionWarningOnGreenNorthRight(t *testing.T) {
 
 	// Set NORTH RIGHT road to GREEN, this should not be a problem
 	var result bool
-	result = CollisionWarningOnGreen(in, "NORTH", "RIGHT")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "RIGHT")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be false, got %v`, result)
 	}
 
 	// Test if we can set NORTH RIGHT to GREEN, when SOUTH RIGHT doesn't exist
 	in.EnableLights("SOUTH", "RIGHT")
-	result = CollisionWarningOnGreen(in, "NORTH", "RIGHT")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "RIGHT")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -202,14 +203,14 @@ func TestCollisionWarningOnGreenNorthForward(t *testing.T) {
 
 	// Set NORTH FORWARD road to GREEN, this should not be a problem
 	var result bool
-	result = CollisionWarningOnGreen(in, "NORTH", "FORWARD")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "FORWARD")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be false, got %v`, result)
 	}
 
 	// Test if we can set NORTH FORWARD to GREEN, when SOUTH FORWARD doesn't exist
 	in.EnableLights("SOUTH", "FORWARD")
-	result = CollisionWarningOnGreen(in, "NORTH", "FORWARD")
+	result = collisonwarning.CollisionWarningOnGreen(in, "NORTH", "FORWARD")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
@@ -218,7 +219,7 @@ func TestCollisionWarningOnGreenNorthForward(t *testing.T) {
 	// Test if we can set NORTH FORWARD to GREEN, when SOUTH FORWARD doesn't exist
 	in.EnableLights("NORTH", "FORWARD")
 	in.EnableLights("NORTH", "RIGHT")
-	result = CollisionWarningOnGreen(in, "SOUTH", "FORWARD")
+	result = collisonwarning.CollisionWarningOnGreen(in, "SOUTH", "FORWARD")
 	if result == true {
 		t.Fatalf(`CollisionWarningOnGreen should be true, got %v`, result)
 	}
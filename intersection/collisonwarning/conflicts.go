@@ -0,0 +1,126 @@
+package collisonwarning
+
+import (
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+// Movement is the way traffic in a lane goes over the intersection
+type Movement struct {
+	Road string
+	Lane string
+}
+
+// The roads clockwise, starting at the top
+var MATRIX_ROADS = []string{"NORTH", "EAST", "SOUTH", "WEST"}
+var MATRIX_LANES = []string{"LEFT", "FORWARD", "RIGHT", "ALL", "CROSSWALK", "BICYCLE"}
+
+// Every road has these points on the edge of the intersection, clockwise.
+// Traffic drives on the right, so traffic comes in on the left side of the road when you look at the intersection,
+// and the bicycle lane and crosswalk are on the outside.
+const (
+	POINT_CROSSWALK_START = iota
+	POINT_BICYCLE_IN
+	POINT_IN
+	POINT_OUT
+	POINT_BICYCLE_OUT
+	POINT_CROSSWALK_END
+	POINTS_PER_ROAD
+)
+
+// CONFLICT_MATRIX has for every movement the movements that cross or merge with it
+var CONFLICT_MATRIX = BuildConflictMatrix()
+
+// path is a line over the intersection from one point to another
+type path [2]int
+
+func BuildConflictMatrix() map[Movement][]Movement {
+	// Create all the movements
+	var movements []Movement
+	for _, roadName := range MATRIX_ROADS {
+		for _, laneName := range MATRIX_LANES {
+			movements = append(movements, Movement{Road: roadName, Lane: laneName})
+		}
+	}
+
+	// Check every movement against all the others
+	matrix := map[Movement][]Movement{}
+	for _, a := range movements {
+		matrix[a] = []Movement{}
+		for _, b := range movements {
+			if a != b && movementsConflict(a, b) {
+				matrix[a] = append(matrix[a], b)
+			}
+		}
+	}
+	return matrix
+}
+
+func HasConflict(a Movement, b Movement) bool {
+	for _, conflict := range CONFLICT_MATRIX[a] {
+		if conflict == b {
+			return true
+		}
+	}
+	return false
+}
+
+func movementsConflict(a Movement, b Movement) bool {
+	for _, pathA := range getPaths(a) {
+		for _, pathB := range getPaths(b) {
+			if pathsConflict(pathA, pathB) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getPaths(m Movement) []path {
+	switch m.Lane {
+	case "CROSSWALK":
+		// Humans walk over the road
+		return []path{{getPoint(m.Road, POINT_CROSSWALK_START), getPoint(m.Road, POINT_CROSSWALK_END)}}
+	case "BICYCLE":
+		// Bicycles go forward
+		return []path{{getPoint(m.Road, POINT_BICYCLE_IN), getPoint(GetOppositeDirection(m.Road), POINT_BICYCLE_OUT)}}
+	}
+
+	// Cars go to the OUTPUT lane of every destination
+	var paths []path
+	for _, destination := range road.GetDestinations(m.Road, m.Lane) {
+		paths = append(paths, path{getPoint(m.Road, POINT_IN), getPoint(destination, POINT_OUT)})
+	}
+	return paths
+}
+
+func getPoint(roadName string, point int) int {
+	for index, name := range MATRIX_ROADS {
+		if name == roadName {
+			return index*POINTS_PER_ROAD + point
+		}
+	}
+	return -1
+}
+
+func pathsConflict(a path, b path) bool {
+	// Paths from the same point split up
+	if a[0] == b[0] {
+		return false
+	}
+
+	// Paths to the same point merge
+	if a[1] == b[1] {
+		return true
+	}
+
+	// Paths cross when one end of b is on each side of a
+	return isBetween(a, b[0]) != isBetween(a, b[1])
+}
+
+// isBetween checks if the point is on the edge clockwise from the start to the end of the path
+func isBetween(p path, point int) bool {
+	total := len(MATRIX_ROADS) * POINTS_PER_ROAD
+	distance := (point - p[0] + total) % total
+	length := (p[1] - p[0] + total) % total
+	return distance > 0 && distance < length
+}
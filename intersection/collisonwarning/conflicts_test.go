@@ -0,0 +1,82 @@
+package collisonwarning
+
+import "testing"
+
+// fakeLights has a light state for every movement
+type fakeLights map[Movement]string
+
+func (f fakeLights) HasLane(roadName string, direction string) bool {
+	return true
+}
+
+func (f fakeLights) GetLaneState(roadName string, direction string) string {
+	state, ok := f[Movement{Road: roadName, Lane: direction}]
+	if !ok {
+		return "RED"
+	}
+	return state
+}
+
+func TestConflictMatrixIsSymmetric(t *testing.T) {
+	for a, conflicts := range CONFLICT_MATRIX {
+		for _, b := range conflicts {
+			if a == b {
+				t.Fatalf(`%v should not conflict with itself`, a)
+			}
+			if !HasConflict(b, a) {
+				t.Fatalf(`%v conflicts with %v, but not the other way around`, a, b)
+			}
+		}
+	}
+}
+
+func TestConflictMatrixTrafficRules(t *testing.T) {
+	tests := []struct {
+		a        Movement
+		b        Movement
+		conflict bool
+		reason   string
+	}{
+		{Movement{"NORTH", "FORWARD"}, Movement{"SOUTH", "FORWARD"}, false, "opposite traffic going forward passes"},
+		{Movement{"NORTH", "LEFT"}, Movement{"SOUTH", "LEFT"}, false, "opposite left turns pass"},
+		{Movement{"NORTH", "LEFT"}, Movement{"SOUTH", "FORWARD"}, true, "a left turn crosses opposite traffic"},
+		{Movement{"NORTH", "FORWARD"}, Movement{"EAST", "FORWARD"}, true, "crossing roads cross"},
+		{Movement{"NORTH", "RIGHT"}, Movement{"EAST", "FORWARD"}, true, "a right turn merges with traffic from the right"},
+		{Movement{"NORTH", "RIGHT"}, Movement{"SOUTH", "FORWARD"}, false, "a right turn stays out of opposite traffic"},
+		{Movement{"NORTH", "LEFT"}, Movement{"SOUTH", "RIGHT"}, true, "both turn into the EAST"},
+		{Movement{"NORTH", "LEFT"}, Movement{"NORTH", "FORWARD"}, false, "lanes of the same road split up"},
+		{Movement{"NORTH", "RIGHT"}, Movement{"NORTH", "BICYCLE"}, true, "a right turn crosses the bicycle lane"},
+		{Movement{"NORTH", "BICYCLE"}, Movement{"SOUTH", "BICYCLE"}, false, "opposite bicycles pass"},
+		{Movement{"NORTH", "BICYCLE"}, Movement{"EAST", "FORWARD"}, true, "bicycles cross the crossing road"},
+		{Movement{"NORTH", "CROSSWALK"}, Movement{"NORTH", "FORWARD"}, true, "traffic from the road crosses the crosswalk"},
+		{Movement{"NORTH", "CROSSWALK"}, Movement{"EAST", "RIGHT"}, true, "traffic into the road crosses the crosswalk"},
+		{Movement{"NORTH", "CROSSWALK"}, Movement{"EAST", "LEFT"}, false, "traffic that stays away from the road passes"},
+		{Movement{"NORTH", "CROSSWALK"}, Movement{"EAST", "CROSSWALK"}, false, "crosswalks don't cross"},
+		{Movement{"NORTH", "CROSSWALK"}, Movement{"SOUTH", "BICYCLE"}, true, "bicycles cross the crosswalk at the end"},
+		{Movement{"NORTH", "ALL"}, Movement{"SOUTH", "FORWARD"}, true, "ALL has the conflicts of the left turn"},
+	}
+	for _, test := range tests {
+		if HasConflict(test.a, test.b) != test.conflict {
+			t.Fatalf(`Conflict between %v and %v should be %v: %s`, test.a, test.b, test.conflict, test.reason)
+		}
+	}
+}
+
+func TestCollisionWarningFollowsMatrix(t *testing.T) {
+	// Turn every movement GREEN once, and check every other movement
+	for green := range CONFLICT_MATRIX {
+		lights := fakeLights{green: "GREEN"}
+		for check := range CONFLICT_MATRIX {
+			expected := HasConflict(check, green)
+			if check.Lane == "ALL" {
+				for _, lane := range []string{"LEFT", "FORWARD", "RIGHT"} {
+					expected = expected || HasConflict(Movement{Road: check.Road, Lane: lane}, green)
+				}
+			}
+			result := CollisionWarningOnGreen(lights, check.Road, check.Lane)
+			if result != expected {
+				t.Fatalf(`CollisionWarningOnGreen for %v with %v GREEN should be %v, got %v`, check, green, expected, result)
+			}
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+var CONTROLLER_MODES = []string{"INTEGRATED", "SEPERATED"}
+var ROAD_NAMES = []string{"NORTH", "EAST", "SOUTH", "WEST"}
+
+const MAX_LANES_PER_DIRECTION uint8 = 5
+
+type Config struct {
+	ControllerMode string   `json:"controllerMode"` // INTEGRATED or SEPERATED
+	Strategy       string   `json:"strategy"`       // How the traffic controller picks the GREEN lights, empty for PATTERN
+	TickSpeed      Duration `json:"tickSpeed"`      // Time between two ticks, like "4s"
+	ApiAddress     string   `json:"apiAddress"`     // Address of the API in SEPERATED mode, like "localhost:8080"
+	Seed           int64    `json:"seed"`           // Seed of the random traffic, 0 picks a new seed every run
+	FastForward    bool     `json:"fastForward"`    // Run the ticks without waiting, only in INTEGRATED mode
+	MaxTicks       int      `json:"maxTicks"`       // Stop after this amount of ticks, 0 runs forever
+	Roads          []Road   `json:"roads"`          // Roads of the intersection
+
+	// Settings of the strategy, 0 for the default
+	StrategySettings trafficcontroller.StrategySettings `json:"strategySettings"`
+
+	// Clearance times of the traffic lights in ticks
+	SignalTiming road.SignalTiming `json:"signalTiming"`
+
+	// Priority for buses that are behind their schedule
+	TransitPriority trafficcontroller.TransitPrioritySettings `json:"transitPriority"`
+
+	// How the traffic controller calls the API in SEPERATED mode, 0 for the default
+	Connection Connection `json:"connection"`
+
+	// Ticks without a light change or heartbeat of the traffic controller before all lights FLASH in SEPERATED mode, 0 for the default
+	WatchdogTimeout int `json:"watchdogTimeout"`
+
+	// Intersections along a corridor, empty for a single intersection
+	Corridor *Corridor `json:"corridor"`
+}
+
+// Corridor are intersections in a row, the EAST road of one intersection leads to the WEST road of the next one
+type Corridor struct {
+	Intersections []CorridorIntersection                 `json:"intersections"` // From WEST to EAST
+	Coordination  trafficcontroller.CoordinationSettings `json:"coordination"`  // Green wave along the corridor
+}
+
+type CorridorIntersection struct {
+	Name       string `json:"name"`       // Name of the intersection, shown in the summary
+	TravelTime int    `json:"travelTime"` // Ticks to drive from the previous intersection, not used for the first one
+	Roads      []Road `json:"roads"`      // Roads of the intersection, empty for the roads of the config
+}
+
+type Connection struct {
+	BaseUrl string   `json:"baseUrl"` // Where the traffic controller finds the API, like "http://localhost:8080", empty for the apiAddress
+	Timeout Duration `json:"timeout"` // Time a request may take, like "500ms"
+	Retries *int     `json:"retries"` // Times a request is tried again when the API can't be reached, nil for the default
+	Backoff Duration `json:"backoff"` // Time before the first retry, it doubles every retry
+}
+
+type Road struct {
+	Name         string       `json:"name"`         // NORTH, EAST, SOUTH or WEST
+	Lanes        Lanes        `json:"lanes"`        // Amount of lanes for each direction
+	Crosswalk    bool         `json:"crosswalk"`    // Whether or not the road has a crosswalk
+	Bicycles     bool         `json:"bicycles"`     // Whether or not the road has a bicycle lane
+	ArrivalRates ArrivalRates `json:"arrivalRates"` // How fast new traffic arrives
+}
+
+type Lanes struct {
+	Left    uint8 `json:"left"`
+	Forward uint8 `json:"forward"`
+	Right   uint8 `json:"right"`
+	All     uint8 `json:"all"`
+}
+
+// ArrivalRates are the amount of ticks between new traffic, 0 picks a random rate
+type ArrivalRates struct {
+	Cars      uint8  `json:"cars"`
+	Humans    uint8  `json:"humans"`
+	Bicycles  uint8  `json:"bicycles"`
+	Emergency uint16 `json:"emergency"` // Emergency vehicles are rare, 0 for none
+	Buses     uint16 `json:"buses"`     // Buses are rare too, 0 for none
+}
+
+// Duration is a time.Duration written as a string in JSON, like "4s" or "500ms"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return fmt.Errorf("duration should be a string like \"4s\", got %s", string(data))
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func Load(path string) (*Config, error) {
+	// Open the file
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open config: %w", err)
+	}
+	defer file.Close()
+
+	// Parse the file, unknown fields are most likely typos
+	var c Config
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&c)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse config %s: %w", path, err)
+	}
+
+	// Check if the config makes sense
+	err = c.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s:\n%w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	// Check the controller
+	if !contains(CONTROLLER_MODES, c.ControllerMode) {
+		errs = append(errs, fmt.Errorf("controllerMode: unknown mode %q, expected one of %s", c.ControllerMode, strings.Join(CONTROLLER_MODES, ", ")))
+	}
+	if c.Strategy != "" && !contains(trafficcontroller.GetStrategyNames(), c.Strategy) {
+		errs = append(errs, fmt.Errorf("strategy: unknown strategy %q, expected one of %s", c.Strategy, strings.Join(trafficcontroller.GetStrategyNames(), ", ")))
+	}
+	if c.StrategySettings.DecisionInterval < 0 {
+		errs = append(errs, fmt.Errorf("strategySettings.decisionInterval: should be 0 or more, got %d", c.StrategySettings.DecisionInterval))
+	}
+	if c.StrategySettings.MinPhaseTime < 0 {
+		errs = append(errs, fmt.Errorf("strategySettings.minPhaseTime: should be 0 or more, got %d", c.StrategySettings.MinPhaseTime))
+	}
+	for _, err := range trafficcontroller.ValidateActuatedTimings(c.StrategySettings.ActuatedPhases) {
+		errs = append(errs, fmt.Errorf("strategySettings.actuatedPhases%w", err))
+	}
+	if c.TransitPriority.MinLateness < 0 {
+		errs = append(errs, fmt.Errorf("transitPriority.minLateness: should be 0 or more, got %d", c.TransitPriority.MinLateness))
+	}
+	if c.TransitPriority.MaxExtension < 0 {
+		errs = append(errs, fmt.Errorf("transitPriority.maxExtension: should be 0 or more, got %d", c.TransitPriority.MaxExtension))
+	}
+	if c.TransitPriority.MaxReduction < 0 {
+		errs = append(errs, fmt.Errorf("transitPriority.maxReduction: should be 0 or more, got %d", c.TransitPriority.MaxReduction))
+	}
+	if c.SignalTiming.RedOrange < 0 {
+		errs = append(errs, fmt.Errorf("signalTiming.redOrange: should be 0 or more, got %d", c.SignalTiming.RedOrange))
+	}
+	if c.SignalTiming.MinOrange < 0 {
+		errs = append(errs, fmt.Errorf("signalTiming.minOrange: should be 0 or more, got %d", c.SignalTiming.MinOrange))
+	}
+	if c.SignalTiming.AllRed < 0 {
+		errs = append(errs, fmt.Errorf("signalTiming.allRed: should be 0 or more, got %d", c.SignalTiming.AllRed))
+	}
+	if c.TickSpeed <= 0 {
+		errs = append(errs, errors.New("tickSpeed: should be more than 0, like \"4s\""))
+	}
+	if c.ControllerMode == "SEPERATED" {
+		_, _, err := net.SplitHostPort(c.ApiAddress)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("apiAddress: should be host:port, like \"localhost:8080\", got %q", c.ApiAddress))
+		}
+	}
+	if c.Connection.BaseUrl != "" {
+		baseUrl, err := url.Parse(c.Connection.BaseUrl)
+		if err != nil || (baseUrl.Scheme != "http" && baseUrl.Scheme != "https") || baseUrl.Host == "" {
+			errs = append(errs, fmt.Errorf("connection.baseUrl: should be an http URL, like \"http://localhost:8080\", got %q", c.Connection.BaseUrl))
+		}
+	}
+	if c.Connection.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("connection.timeout: should be 0 or more, got %s", time.Duration(c.Connection.Timeout)))
+	}
+	if c.Connection.Retries != nil && *c.Connection.Retries < 0 {
+		errs = append(errs, fmt.Errorf("connection.retries: should be 0 or more, got %d", *c.Connection.Retries))
+	}
+	if c.Connection.Backoff < 0 {
+		errs = append(errs, fmt.Errorf("connection.backoff: should be 0 or more, got %s", time.Duration(c.Connection.Backoff)))
+	}
+	if c.WatchdogTimeout < 0 {
+		errs = append(errs, fmt.Errorf("watchdogTimeout: should be 0 or more, got %d", c.WatchdogTimeout))
+	}
+
+	// Check the simulation
+	if c.FastForward && c.ControllerMode != "INTEGRATED" {
+		errs = append(errs, errors.New("fastForward: only works in INTEGRATED mode, the SEPERATED controller runs on real time and its heartbeat would fall behind"))
+	}
+	if c.MaxTicks < 0 {
+		errs = append(errs, fmt.Errorf("maxTicks: should be 0 or more, got %d", c.MaxTicks))
+	}
+
+	// Check the roads
+	errs = append(errs, validateRoads("roads", c.Roads)...)
+
+	// Check the corridor
+	if c.Corridor != nil {
+		errs = append(errs, c.validateCorridor()...)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateCorridor() []error {
+	var errs []error
+	if c.ControllerMode != "INTEGRATED" {
+		errs = append(errs, errors.New("corridor: only works in INTEGRATED mode, every intersection has its own traffic controller"))
+	}
+	if len(c.Corridor.Intersections) < 2 {
+		errs = append(errs, fmt.Errorf("corridor.intersections: a corridor needs at least 2 intersections, got %d", len(c.Corridor.Intersections)))
+	}
+	seenNames := map[string]bool{}
+	for index, in := range c.Corridor.Intersections {
+		field := fmt.Sprintf("corridor.intersections[%d]", index)
+		if in.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: every intersection needs a name", field))
+		} else if seenNames[in.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: intersection %s is defined twice", field, in.Name))
+		}
+		seenNames[in.Name] = true
+		if in.TravelTime < 0 {
+			errs = append(errs, fmt.Errorf("%s.travelTime: should be 0 or more, got %d", field, in.TravelTime))
+		}
+
+		// The corridor drives over the WEST and EAST roads
+		roads := c.GetCorridorRoads(index)
+		if len(in.Roads) > 0 {
+			errs = append(errs, validateRoads(field+".roads", in.Roads)...)
+		}
+		for _, name := range []string{"WEST", "EAST"} {
+			if !hasRoad(roads, name) {
+				errs = append(errs, fmt.Errorf("%s.roads: the corridor needs the %s road", field, name))
+			}
+		}
+	}
+
+	// Check the green wave
+	coordination := c.Corridor.Coordination
+	if coordination.CycleLength < 0 {
+		errs = append(errs, fmt.Errorf("corridor.coordination.cycleLength: should be 0 or more, got %d", coordination.CycleLength))
+	}
+	if coordination.CorridorGreen < 0 {
+		errs = append(errs, fmt.Errorf("corridor.coordination.corridorGreen: should be 0 or more, got %d", coordination.CorridorGreen))
+	}
+	coordination = coordination.WithDefaults()
+	if coordination.CorridorGreen >= coordination.CycleLength {
+		errs = append(errs, fmt.Errorf("corridor.coordination.corridorGreen: should be less than the cycle length of %d ticks, got %d", coordination.CycleLength, coordination.CorridorGreen))
+	}
+	return errs
+}
+
+// GetCorridorRoads returns the roads of an intersection of the corridor, the roads of the config by default
+func (c *Config) GetCorridorRoads(index int) []Road {
+	if len(c.Corridor.Intersections[index].Roads) > 0 {
+		return c.Corridor.Intersections[index].Roads
+	}
+	return c.Roads
+}
+
+// GetTravelTimes returns the ticks to drive between every two intersections of the corridor
+func (c *Corridor) GetTravelTimes() []int {
+	var travelTimes []int
+	for _, in := range c.Intersections[1:] {
+		travelTimes = append(travelTimes, in.TravelTime)
+	}
+	return travelTimes
+}
+
+func validateRoads(prefix string, roads []Road) []error {
+	var errs []error
+	if len(roads) == 0 {
+		errs = append(errs, fmt.Errorf("%s: the intersection needs at least one road", prefix))
+	}
+	seenRoads := map[string]bool{}
+	for index, r := range roads {
+		field := fmt.Sprintf("%s[%d]", prefix, index)
+		if !contains(ROAD_NAMES, r.Name) {
+			errs = append(errs, fmt.Errorf("%s.name: unknown road %q, expected one of %s", field, r.Name, strings.Join(ROAD_NAMES, ", ")))
+		} else if seenRoads[r.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: road %s is defined twice", field, r.Name))
+		}
+		seenRoads[r.Name] = true
+
+		// Check the lanes
+		lanes := map[string]uint8{"left": r.Lanes.Left, "forward": r.Lanes.Forward, "right": r.Lanes.Right, "all": r.Lanes.All}
+		totalLanes := 0
+		for _, direction := range []string{"left", "forward", "right", "all"} {
+			if lanes[direction] > MAX_LANES_PER_DIRECTION {
+				errs = append(errs, fmt.Errorf("%s.lanes.%s: at most %d lanes, got %d", field, direction, MAX_LANES_PER_DIRECTION, lanes[direction]))
+			}
+			totalLanes += int(lanes[direction])
+		}
+		if totalLanes == 0 && !r.Crosswalk && !r.Bicycles {
+			errs = append(errs, fmt.Errorf("%s.lanes: road %s needs at least one lane", field, r.Name))
+		}
+	}
+	return errs
+}
+
+func hasRoad(roads []Road, name string) bool {
+	for _, r := range roads {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetApiUrl returns the URL of the API on the apiAddress, an address without a host like ":8080" is on localhost
+func (c *Config) GetApiUrl() string {
+	host, port, err := net.SplitHostPort(c.ApiAddress)
+	if err != nil {
+		return "http://" + c.ApiAddress
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// GetConnectionSettings returns how the SEPERATED traffic controller calls the API, by default on the apiAddress
+func (c *Config) GetConnectionSettings() trafficcontroller.ConnectionSettings {
+	baseUrl := c.Connection.BaseUrl
+	if baseUrl == "" {
+		baseUrl = c.GetApiUrl()
+	}
+	retries := trafficcontroller.DEFAULT_RETRIES
+	if c.Connection.Retries != nil {
+		retries = *c.Connection.Retries
+	}
+	return trafficcontroller.ConnectionSettings{
+		BaseUrl: strings.TrimSuffix(baseUrl, "/"),
+		Timeout: time.Duration(c.Connection.Timeout),
+		Retries: retries,
+		Backoff: time.Duration(c.Connection.Backoff),
+	}
+}
+
+func (r Road) ToRoadInput(random *rand.Rand) road.NewRoadInput {
+	return road.NewRoadInput{
+		Name:             r.Name,
+		Left:             r.Lanes.Left,
+		Right:            r.Lanes.Right,
+		Forward:          r.Lanes.Forward,
+		All:              r.Lanes.All,
+		CrossWalkEnabled: r.Crosswalk,
+		BycyclesEnabled:  r.Bicycles,
+		NewCarSpeed:      r.ArrivalRates.Cars,
+		NewHumanSpeed:    r.ArrivalRates.Humans,
+		NewBycycleSpeed:  r.ArrivalRates.Bicycles,
+		EmergencySpeed:   r.ArrivalRates.Emergency,
+		BusSpeed:         r.ArrivalRates.Buses,
+		Random:           random,
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
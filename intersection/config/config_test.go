@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "intersection.json")
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadValidConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"controllerMode": "INTEGRATED",
+		"tickSpeed": "500ms",
+		"roads": [
+			{"name": "NORTH", "lanes": {"left": 1, "forward": 2}, "crosswalk": true, "arrivalRates": {"cars": 3}},
+			{"name": "SOUTH", "lanes": {"all": 1}}
+		]
+	}`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf(`Load returned an error: %v`, err)
+	}
+	if time.Duration(c.TickSpeed) != 500*time.Millisecond {
+		t.Fatalf(`TickSpeed should be 500ms, got %s`, time.Duration(c.TickSpeed))
+	}
+	input := c.Roads[0].ToRoadInput(nil)
+	if input.Name != "NORTH" || input.Left != 1 || input.Forward != 2 || !input.CrossWalkEnabled || input.NewCarSpeed != 3 {
+		t.Fatalf(`Road input does not match the config: %+v`, input)
+	}
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"controllerMode": "MANUAL",
+		"strategy": "RANDOM",
+		"tickSpeed": "1s",
+		"fastForward": true,
+		"maxTicks": -1,
+		"roads": [
+			{"name": "NORTH", "lanes": {"left": 6}},
+			{"name": "NORTH", "lanes": {"all": 1}},
+			{"name": "UP", "lanes": {}}
+		]
+	}`)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatalf(`Load should return an error`)
+	}
+	for _, expected := range []string{"controllerMode", "strategy", "fastForward", "maxTicks", "roads[0].lanes.left", "roads[1].name", "roads[2].name", "roads[2].lanes"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf(`Error should mention %s, got: %v`, expected, err)
+		}
+	}
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	path := writeConfig(t, `{"controllerMode": "INTEGRATED", "tickSpeed": "1s", "tickSped": "2s", "roads": []}`)
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "tickSped") {
+		t.Fatalf(`Load should fail on the unknown field, got: %v`, err)
+	}
+}
+
+func TestLoadInvalidCorridor(t *testing.T) {
+	path := writeConfig(t, `{
+		"controllerMode": "SEPERATED",
+		"tickSpeed": "1s",
+		"apiAddress": "localhost:8080",
+		"roads": [{"name": "WEST", "lanes": {"forward": 1}}, {"name": "EAST", "lanes": {"forward": 1}}],
+		"corridor": {
+			"intersections": [
+				{"name": "A"},
+				{"name": "A", "travelTime": -1},
+				{"name": "B", "roads": [{"name": "WEST", "lanes": {"forward": 1}}]}
+			],
+			"coordination": {"enabled": true, "cycleLength": 40, "corridorGreen": 40}
+		}
+	}`)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatalf(`Load should return an error`)
+	}
+	for _, expected := range []string{"corridor: only works in INTEGRATED", "intersections[1].name", "intersections[1].travelTime", "intersections[2].roads: the corridor needs the EAST road", "corridorGreen"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf(`Error should mention %s, got: %v`, expected, err)
+		}
+	}
+}
+
+func TestLoadConnection(t *testing.T) {
+	// The traffic controller finds the API on the apiAddress by default
+	path := writeConfig(t, `{
+		"controllerMode": "SEPERATED",
+		"tickSpeed": "1s",
+		"apiAddress": "localhost:8080",
+		"roads": [{"name": "NORTH", "lanes": {"forward": 1}}]
+	}`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf(`Load should not return an error, got: %v`, err)
+	}
+	if settings := c.GetConnectionSettings(); settings.BaseUrl != "http://localhost:8080" || settings.Retries != trafficcontroller.DEFAULT_RETRIES {
+		t.Fatalf(`Base URL should be http://localhost:8080 with the default retries, got %+v`, settings)
+	}
+
+	// 0 retries turns them off
+	retries := 0
+	c.Connection.Retries = &retries
+	if settings := c.GetConnectionSettings(); settings.Retries != 0 {
+		t.Fatalf(`Retries should be off, got %d`, settings.Retries)
+	}
+
+	// An address without a host is on localhost
+	for address, url := range map[string]string{":8080": "http://localhost:8080", "0.0.0.0:80": "http://localhost:80", "[::1]:8080": "http://[::1]:8080"} {
+		c.ApiAddress = address
+		if settings := c.GetConnectionSettings(); settings.BaseUrl != url {
+			t.Fatalf(`Base URL of %s should be %s, got %s`, address, url, settings.BaseUrl)
+		}
+	}
+
+	// Invalid settings are refused
+	path = writeConfig(t, `{
+		"controllerMode": "SEPERATED",
+		"tickSpeed": "1s",
+		"apiAddress": "localhost:8080",
+		"connection": {"baseUrl": "localhost:8080", "retries": -1, "timeout": "-1s"},
+		"watchdogTimeout": -1,
+		"roads": [{"name": "NORTH", "lanes": {"forward": 1}}]
+	}`)
+	_, err = Load(path)
+	if err == nil {
+		t.Fatalf(`Load should return an error`)
+	}
+	for _, expected := range []string{"connection.baseUrl", "connection.retries", "connection.timeout", "watchdogTimeout"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf(`Error should mention %s, got: %v`, expected, err)
+		}
+	}
+
+	// The traffic controller runs on real time, the heartbeat can't keep up with fast forward
+	path = writeConfig(t, `{
+		"controllerMode": "SEPERATED",
+		"tickSpeed": "1s",
+		"apiAddress": "localhost:8080",
+		"fastForward": true,
+		"roads": [{"name": "NORTH", "lanes": {"forward": 1}}]
+	}`)
+	_, err = Load(path)
+	if err == nil || !strings.Contains(err.Error(), "fastForward") {
+		t.Fatalf(`Fast forward should be refused in SEPERATED mode, got: %v`, err)
+	}
+}
+
+func TestLoadActuatedPhases(t *testing.T) {
+	path := writeConfig(t, `{
+		"controllerMode": "INTEGRATED",
+		"strategy": "ACTUATED",
+		"tickSpeed": "1s",
+		"strategySettings": {"actuatedPhases": [{"phase": 3, "minGreen": 2, "maxGreen": 15}]},
+		"roads": [{"name": "NORTH", "lanes": {"forward": 1}}]
+	}`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf(`Load should not return an error, got: %v`, err)
+	}
+	if phase := trafficcontroller.GetActuatedPhases(c.StrategySettings.ActuatedPhases)[3]; phase.MinGreen != 2 || phase.MaxGreen != 15 {
+		t.Fatalf(`Phase 3 should get the timing of the config, got %+v`, phase)
+	}
+
+	// Invalid timings are refused
+	path = writeConfig(t, `{
+		"controllerMode": "INTEGRATED",
+		"strategy": "ACTUATED",
+		"tickSpeed": "1s",
+		"strategySettings": {"actuatedPhases": [{"phase": 20}, {"phase": 1, "gapTime": -1}, {"phase": 2, "minGreen": 10, "maxGreen": 5}]},
+		"roads": [{"name": "NORTH", "lanes": {"forward": 1}}]
+	}`)
+	_, err = Load(path)
+	if err == nil {
+		t.Fatalf(`Load should return an error`)
+	}
+	for _, expected := range []string{"actuatedPhases[0].phase", "actuatedPhases[1].gapTime", "actuatedPhases[2].maxGreen"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf(`Error should mention %s, got: %v`, expected, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"reflect"
+	"sync"
+)
+
+// LaneCongested is published when a lane without a GREEN light has a lot of traffic waiting
+type LaneCongested struct {
+	Tick int    `json:"tick"`
+	Road string `json:"road"`
+	Lane string `json:"lane"`
+}
+
+// LaneEmptied is published when the last traffic of a lane crossed
+type LaneEmptied struct {
+	Tick int    `json:"tick"`
+	Road string `json:"road"`
+	Lane string `json:"lane"`
+}
+
+// LightChanged is published when the light of a lane changes
+type LightChanged struct {
+	Tick  int    `json:"tick"`
+	Road  string `json:"road"`
+	Lane  string `json:"lane"`
+	From  string `json:"from"`
+	State string `json:"state"`
+}
+
+// VehicleCrossed is published for every vehicle, human or bicycle that crossed
+type VehicleCrossed struct {
+	Tick  int    `json:"tick"`
+	Road  string `json:"road"`
+	Lane  string `json:"lane"`
+	Delay int    `json:"delay"` // Ticks the traffic waited
+}
+
+// EmergencyVehicleArrived is published when an emergency vehicle needs GREEN
+type EmergencyVehicleArrived struct {
+	Tick int    `json:"tick"`
+	Road string `json:"road"`
+	Lane string `json:"lane"`
+}
+
+// BusArrived is published when a bus arrives, with the ticks it is behind its schedule
+type BusArrived struct {
+	Tick     int    `json:"tick"`
+	Road     string `json:"road"`
+	Lane     string `json:"lane"`
+	Lateness int    `json:"lateness"`
+}
+
+// Ticked is published at the end of every tick, after the traffic moved
+type Ticked struct {
+	Tick int `json:"tick"`
+}
+
+// EVENT_TYPES has an empty event of every type that is published
+var EVENT_TYPES = []any{LaneCongested{}, LaneEmptied{}, LightChanged{}, VehicleCrossed{}, EmergencyVehicleArrived{}, BusArrived{}, Ticked{}}
+
+// Bus delivers the events of one intersection to its subscribers, in the order they subscribed
+type Bus struct {
+	mutex         sync.Mutex
+	subscriptions []*Subscription
+}
+
+type Subscription struct {
+	bus     *Bus
+	handler func(event any)
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls the handler for every event of type E
+func Subscribe[E any](bus *Bus, handler func(event E)) *Subscription {
+	return SubscribeAll(bus, func(event any) {
+		if typed, ok := event.(E); ok {
+			handler(typed)
+		}
+	})
+}
+
+// SubscribeAll calls the handler for every event
+func SubscribeAll(bus *Bus, handler func(event any)) *Subscription {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	subscription := &Subscription{bus: bus, handler: handler}
+	bus.subscriptions = append(bus.subscriptions, subscription)
+	return subscription
+}
+
+// Unsubscribe stops the handler, it is safe to call more than once
+func (s *Subscription) Unsubscribe() {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+	for index, subscription := range s.bus.subscriptions {
+		if subscription == s {
+			s.bus.subscriptions = append(s.bus.subscriptions[:index:index], s.bus.subscriptions[index+1:]...)
+			return
+		}
+	}
+}
+
+// Publish calls the handlers right away, a handler can subscribe or unsubscribe without blocking the bus
+func (b *Bus) Publish(event any) {
+	b.mutex.Lock()
+	subscriptions := b.subscriptions
+	b.mutex.Unlock()
+	for _, subscription := range subscriptions {
+		subscription.handler(event)
+	}
+}
+
+// GetSubscriptionCount returns the amount of handlers, to check that nothing was left behind
+func (b *Bus) GetSubscriptionCount() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscriptions)
+}
+
+// GetName returns the name of the type of an event, like LaneCongested
+func GetName(event any) string {
+	return reflect.TypeOf(event).Name()
+}
+
+// GetNames returns the names of all event types
+func GetNames() []string {
+	var names []string
+	for _, event := range EVENT_TYPES {
+		names = append(names, GetName(event))
+	}
+	return names
+}
+
+// Queue keeps the events of a bus until Deliver, for subscribers that run in another goroutine than the intersection.
+// The subscribers of the queue subscribe to its own bus.
+type Queue struct {
+	mutex        sync.Mutex
+	pending      []any
+	bus          *Bus
+	subscription *Subscription
+}
+
+func NewQueue(source *Bus) *Queue {
+	q := &Queue{bus: NewBus()}
+	q.subscription = SubscribeAll(source, q.add)
+	return q
+}
+
+func (q *Queue) add(event any) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.pending = append(q.pending, event)
+}
+
+// GetBus returns the bus the events are delivered on
+func (q *Queue) GetBus() *Bus {
+	return q.bus
+}
+
+// Deliver publishes the events that were queued since the last call, in the goroutine of the caller
+func (q *Queue) Deliver() {
+	q.mutex.Lock()
+	pending := q.pending
+	q.pending = nil
+	q.mutex.Unlock()
+	for _, event := range pending {
+		q.bus.Publish(event)
+	}
+}
+
+// Close stops queueing the events of the source
+func (q *Queue) Close() {
+	q.subscription.Unsubscribe()
+}
@@ -0,0 +1,68 @@
+package events
+
+import "testing"
+
+func TestSubscribeByType(t *testing.T) {
+	bus := NewBus()
+	var congested []LaneCongested
+	all := 0
+	Subscribe(bus, func(e LaneCongested) { congested = append(congested, e) })
+	SubscribeAll(bus, func(e any) { all++ })
+
+	bus.Publish(LaneCongested{Road: "NORTH", Lane: "LEFT"})
+	bus.Publish(LaneEmptied{Road: "NORTH", Lane: "LEFT"})
+	if len(congested) != 1 || congested[0].Road != "NORTH" || all != 2 {
+		t.Fatalf(`Handler should only get its own type, got %v and %d events`, congested, all)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	first := Subscribe(bus, func(e LaneEmptied) { count++ })
+	Subscribe(bus, func(e LaneEmptied) { count += 10 })
+	first.Unsubscribe()
+	first.Unsubscribe()
+
+	bus.Publish(LaneEmptied{})
+	if count != 10 || bus.GetSubscriptionCount() != 1 {
+		t.Fatalf(`Only the second handler should be called, got %d with %d subscriptions`, count, bus.GetSubscriptionCount())
+	}
+}
+
+func TestBusesAreSeparate(t *testing.T) {
+	first := NewBus()
+	second := NewBus()
+	count := 0
+	Subscribe(first, func(e BusArrived) { count++ })
+	second.Publish(BusArrived{Lateness: 10})
+	if count != 0 {
+		t.Fatalf(`Events of one bus should not reach the handlers of another bus`)
+	}
+}
+
+func TestQueueDeliversLater(t *testing.T) {
+	source := NewBus()
+	queue := NewQueue(source)
+	var emptied []LaneEmptied
+	Subscribe(queue.GetBus(), func(e LaneEmptied) { emptied = append(emptied, e) })
+
+	// The events wait in the queue until they are delivered, in the same order
+	source.Publish(LaneEmptied{Tick: 1})
+	source.Publish(LaneEmptied{Tick: 2})
+	if len(emptied) != 0 {
+		t.Fatalf(`Events should wait in the queue, got %v`, emptied)
+	}
+	queue.Deliver()
+	if len(emptied) != 2 || emptied[0].Tick != 1 || emptied[1].Tick != 2 {
+		t.Fatalf(`Events should be delivered in order, got %v`, emptied)
+	}
+
+	// A closed queue no longer gets events
+	queue.Close()
+	source.Publish(LaneEmptied{Tick: 3})
+	queue.Deliver()
+	if len(emptied) != 2 {
+		t.Fatalf(`A closed queue should not deliver new events, got %v`, emptied)
+	}
+}
@@ -1,18 +1,54 @@
 package intersection
 
 import (
+	"fmt"
+	"sync"
+
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
 	"github.com/martijnwiekens/go-learning/gointersection/road"
 )
 
 type Intersection struct {
 	CurrentTick int
 	roads       []*road.Road
+	monitor     *ConflictMonitor
+	watchdog    *Watchdog                 // Puts the lights on FLASH when the traffic controller is lost, nil when it is off
+	routes      *metrics.ODMatrix         // Vehicles that crossed by origin and destination road
+	name        string                    // Name of the intersection in a network, empty for a single intersection
+	exited      map[string][]road.Traffic // Traffic that left the intersection in the last tick by road
+	events      *events.Bus               // Events of the roads, the traffic controller, API and UI subscribe to them
+
+	// The API reads and changes the intersection between the ticks, see View and Update
+	mutex sync.RWMutex
 }
 
 func NewIntersection(roads []*road.Road) *Intersection {
-	return &Intersection{
-		roads: roads,
+	i := &Intersection{
+		roads:   roads,
+		monitor: NewConflictMonitor(),
+		routes:  metrics.NewODMatrix(),
+		events:  events.NewBus(),
+	}
+	for _, r := range roads {
+		r.SetEvents(i.events)
 	}
+	return i
+}
+
+// SetName names the intersection in a network
+func (i *Intersection) SetName(name string) {
+	i.name = name
+}
+
+func (i *Intersection) GetName() string {
+	return i.name
+}
+
+// GetEvents returns the bus with the events of this intersection only
+func (i *Intersection) GetEvents() *events.Bus {
+	return i.events
 }
 
 func (i *Intersection) GetRoads() []*road.Road {
@@ -28,7 +64,27 @@ func (i *Intersection) GetRoadByName(name string) *road.Road {
 	return nil
 }
 
+// View runs read between two ticks, so everything it reads belongs to the same tick.
+// Other views can run at the same time, read must not change the intersection.
+func (i *Intersection) View(read func()) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	read()
+}
+
+// Update runs write between two ticks, no tick or view sees its changes halfway
+func (i *Intersection) Update(write func() error) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return write()
+}
+
+// Tick moves the traffic, View and Update wait until the tick is done.
+// The subscribers of the events are called during the tick, they can't use View or Update.
 func (i *Intersection) Tick(currentTick int) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	// Remember the tick
 	i.CurrentTick = currentTick
 
@@ -36,52 +92,197 @@ func (i *Intersection) Tick(currentTick int) {
 	for _, r := range i.roads {
 		r.Tick(currentTick)
 	}
+
+	// Move the traffic that crossed to the OUTPUT lane of the next road
+	for _, r := range i.roads {
+		for _, departure := range r.TakeDepartedTraffic() {
+			i.routes.Record(departure.Origin, departure.Destination)
+			destination := i.GetRoadByName(departure.Destination)
+			if destination != nil {
+				destination.AddOutputTraffic(departure.Traffic)
+			}
+		}
+	}
+
+	// Keep the traffic that left the intersection this tick, a network drives it to the next intersection
+	i.exited = map[string][]road.Traffic{}
+	for _, r := range i.roads {
+		if exited := r.TakeExitedTraffic(); len(exited) > 0 {
+			i.exited[r.GetName()] = exited
+		}
+	}
+
+	// Let the lights FLASH when the traffic controller is lost
+	if i.watchdog != nil && i.watchdog.Check(currentTick) {
+		i.DisableLights()
+	}
+
+	// Tell the subscribers the tick is done
+	i.events.Publish(events.Ticked{Tick: currentTick})
 }
 
-func (i *Intersection) FullStopLights() {
+// GetExitedTraffic returns the traffic that left the intersection in the last tick by road
+func (i *Intersection) GetExitedTraffic() map[string][]road.Traffic {
+	return i.exited
+}
+
+// FullStopLights makes all lights RED, without the clearance times, to take control of the intersection
+func (i *Intersection) FullStopLights() bool {
+	// The conflict monitor keeps the lights on FLASH
+	if i.monitor.IsTripped() {
+		return false
+	}
+	if i.watchdog != nil {
+		i.watchdog.Reset(i.CurrentTick)
+	}
 	for _, r := range i.roads {
 		for _, l := range r.GetLanes() {
-			l.SetState("RED")
+			l.ForceState(road.STATE_RED)
 		}
 	}
+	return true
 }
 
 func (i *Intersection) DisableLights() {
 	for _, r := range i.roads {
 		for _, l := range r.GetLanes() {
-			l.SetState("FLASH")
+			l.ForceState(road.STATE_FLASH)
 		}
 	}
 }
 
-func (i *Intersection) EnableLights(roadName string, direction string) {
-	road := i.GetRoadByName(roadName)
-	if road != nil {
-		lanes := road.GetLanesByName(direction)
+func (i *Intersection) EnableLights(roadName string, direction string) bool {
+	r := i.GetRoadByName(roadName)
+	if r == nil {
+		return false
+	}
+	return i.changeLights(roadName, r.GetLanesByName(direction), road.STATE_GREEN) == nil
+}
+
+func (i *Intersection) SetLights(roadName string, direction string, state road.LightState) error {
+	// Use all the lanes
+	r := i.GetRoadByName(roadName)
+	if r == nil {
+		return fmt.Errorf("road %s doesn't exist", roadName)
+	}
+	lanes := r.GetLanesByName(direction)
+	if len(lanes) == 0 {
+		return fmt.Errorf("lane %s:%s doesn't exist", roadName, direction)
+	}
+
+	// ALL also changes the LEFT, FORWARD and RIGHT lanes, none of them change when one of them can't
+	if direction == "ALL" {
+		for _, laneName := range []string{"LEFT", "FORWARD", "RIGHT"} {
+			lanes = append(lanes, r.GetLanesByName(laneName)...)
+		}
+	}
+	return i.changeLights(roadName, lanes, state)
+}
+
+func (i *Intersection) changeLights(roadName string, lanes []*road.Lane, state road.LightState) error {
+	// The conflict monitor keeps the lights on FLASH
+	if i.monitor.IsTripped() {
+		return ErrConflictMonitorTripped
+	}
+
+	// The watchdog keeps the lights on FLASH until the traffic controller stops all lights
+	if i.IsFailSafe() {
+		return ErrWatchdogTripped
+	}
+
+	// Check all the lanes first, so either all lights change or none
+	for _, l := range lanes {
+		err := l.CanSetState(state)
+		if err != nil {
+			return fmt.Errorf("%s:%s %w", roadName, l.GetDirection(), err)
+		}
+	}
+	if state == road.STATE_GREEN || state == road.STATE_RED_ORANGE {
 		for _, l := range lanes {
-			l.SetState("GREEN")
+			err := i.checkClearance(roadName, l)
+			if err != nil {
+				return err
+			}
 		}
 	}
+
+	// The conflict monitor checks the new lights before they change
+	if !i.checkConflicts(lanes, state) {
+		return ErrConflictMonitorTripped
+	}
+
+	// Change the lights
+	for _, l := range lanes {
+		l.ForceState(state)
+	}
+	i.Heartbeat()
+	return nil
 }
 
-func (i *Intersection) SetLights(roadName string, direction string, state string) bool {
-	// Use all the lanes
-	lightsSet := false
-	road := i.GetRoadByName(roadName)
-	if road != nil {
-		laneNames := []string{direction}
-		if direction == "ALL" {
-			laneNames = []string{"LEFT", "FORWARD", "RIGHT", "ALL"}
-		}
-		for _, laneName := range laneNames {
-			lanes := road.GetLanesByName(laneName)
-			for _, lane := range lanes {
-				lane.SetState(state)
-				lightsSet = true
+// checkClearance makes sure the lanes that cross the lane were RED for the all red time
+func (i *Intersection) checkClearance(roadName string, l *road.Lane) error {
+	allRed := l.GetSignalTiming().AllRed
+	if allRed == 0 {
+		return nil
+	}
+	for _, conflict := range collisonwarning.CONFLICT_MATRIX[collisonwarning.Movement{Road: roadName, Lane: l.GetDirection()}] {
+		r := i.GetRoadByName(conflict.Road)
+		if r == nil {
+			continue
+		}
+		for _, other := range r.GetLanesByName(conflict.Lane) {
+			if other.GetState() == road.STATE_RED && other.GetTicksInState() < allRed {
+				return fmt.Errorf("%s:%s can't turn %s yet, %s:%s is RED for %d of %d ticks", roadName, l.GetDirection(), road.STATE_GREEN, conflict.Road, conflict.Lane, other.GetTicksInState(), allRed)
 			}
 		}
 	}
-	return lightsSet
+	return nil
+}
+
+// checkConflicts lets the conflict monitor check the lights as if the lanes had the state,
+// returns false when it tripped and the lights FLASH
+func (i *Intersection) checkConflicts(lanes []*road.Lane, state road.LightState) bool {
+	proposed := make(map[*road.Lane]road.LightState, len(lanes))
+	for _, l := range lanes {
+		proposed[l] = state
+	}
+	if i.monitor.Check(i.CurrentTick, i.roads, proposed) {
+		i.DisableLights()
+		return false
+	}
+	return true
+}
+
+// EnableWatchdog lets the lights FLASH when the traffic controller didn't change a light or send a heartbeat
+// for timeout ticks, 0 for the default
+func (i *Intersection) EnableWatchdog(timeout int) {
+	i.watchdog = NewWatchdog(timeout, i.CurrentTick)
+}
+
+// Heartbeat tells the watchdog the traffic controller is still there
+func (i *Intersection) Heartbeat() {
+	if i.watchdog != nil {
+		i.watchdog.Heartbeat(i.CurrentTick)
+	}
+}
+
+// IsFailSafe returns true when the watchdog lost the traffic controller and the lights FLASH
+func (i *Intersection) IsFailSafe() bool {
+	return i.watchdog != nil && i.watchdog.IsTripped()
+}
+
+func (i *Intersection) IsFaulted() bool {
+	return i.monitor.IsTripped()
+}
+
+func (i *Intersection) GetFaults() []Fault {
+	return i.monitor.GetFaults()
+}
+
+// ResetFaults accepts new light changes again, all lights start on RED
+func (i *Intersection) ResetFaults() {
+	i.monitor.Reset()
+	i.FullStopLights()
 }
 
 func (i *Intersection) GetWaitingTrafficByLane(roadName string, direction string) int {
@@ -96,6 +297,18 @@ func (i *Intersection) GetWaitingTrafficByLane(roadName string, direction string
 	return waitingTraffic
 }
 
+// GetArrivedTrafficByLane returns the traffic that arrived in the lanes since the start, also the traffic that crossed
+func (i *Intersection) GetArrivedTrafficByLane(roadName string, direction string) int {
+	var arrivedTraffic int = 0
+	road := i.GetRoadByName(roadName)
+	if road != nil {
+		for _, lane := range road.GetLanesByName(direction) {
+			arrivedTraffic += lane.GetArrivedTrafficCount()
+		}
+	}
+	return arrivedTraffic
+}
+
 func (i *Intersection) GetWaitingTraffic() int {
 	var waitingTraffic int = 0
 	for _, r := range i.roads {
@@ -122,8 +335,121 @@ func (i *Intersection) GetLaneState(roadName string, direction string) string {
 	if road != nil {
 		lanes := road.GetLanesByName(direction)
 		if len(lanes) > 0 {
-			return lanes[0].GetState()
+			return string(lanes[0].GetState())
 		}
 	}
 	return "FLASH"
 }
+
+// PressButton registers a call for a crosswalk or bicycle lane
+func (i *Intersection) PressButton(roadName string, direction string) error {
+	if !road.IsCrossingLane(direction) {
+		return fmt.Errorf("lane %s:%s has no button, only CROSSWALK and BICYCLE lanes have one", roadName, direction)
+	}
+	r := i.GetRoadByName(roadName)
+	if r == nil || len(r.GetLanesByName(direction)) == 0 {
+		return fmt.Errorf("lane %s:%s doesn't exist", roadName, direction)
+	}
+	for _, l := range r.GetLanesByName(direction) {
+		l.PressButton()
+	}
+	return nil
+}
+
+func (i *Intersection) IsCalled(roadName string, direction string) bool {
+	r := i.GetRoadByName(roadName)
+	if r == nil {
+		return false
+	}
+	for _, l := range r.GetLanesByName(direction) {
+		if l.IsCalled() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddEmergencyVehicle puts an emergency vehicle in a lane for cars, the traffic controller preempts the lights for it
+func (i *Intersection) AddEmergencyVehicle(roadName string, direction string) error {
+	if direction == "OUTPUT" || road.IsCrossingLane(direction) {
+		return fmt.Errorf("lane %s:%s is not a lane for cars", roadName, direction)
+	}
+	r := i.GetRoadByName(roadName)
+	if r == nil || len(r.GetLanesByName(direction)) == 0 {
+		return fmt.Errorf("lane %s:%s doesn't exist", roadName, direction)
+	}
+	r.GetLanesByName(direction)[0].AddEmergencyVehicle(i.CurrentTick)
+	return nil
+}
+
+func (i *Intersection) HasEmergencyVehicle(roadName string, direction string) bool {
+	r := i.GetRoadByName(roadName)
+	if r == nil {
+		return false
+	}
+	for _, l := range r.GetLanesByName(direction) {
+		if l.HasEmergencyVehicle() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBus puts a bus in a lane for cars, the lateness is the ticks it is behind its schedule
+func (i *Intersection) AddBus(roadName string, direction string, lateness int) error {
+	if direction == "OUTPUT" || road.IsCrossingLane(direction) {
+		return fmt.Errorf("lane %s:%s is not a lane for cars", roadName, direction)
+	}
+	r := i.GetRoadByName(roadName)
+	if r == nil || len(r.GetLanesByName(direction)) == 0 {
+		return fmt.Errorf("lane %s:%s doesn't exist", roadName, direction)
+	}
+	r.GetLanesByName(direction)[0].AddBus(i.CurrentTick, lateness)
+	return nil
+}
+
+func (i *Intersection) HasBus(roadName string, direction string) bool {
+	r := i.GetRoadByName(roadName)
+	if r == nil {
+		return false
+	}
+	for _, l := range r.GetLanesByName(direction) {
+		if l.HasBus() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCrossingTime returns the ticks humans need to cross the road, or bicycles need to cross the intersection
+func (i *Intersection) GetCrossingTime(roadName string, direction string) int {
+	r := i.GetRoadByName(roadName)
+	if r == nil || !road.IsCrossingLane(direction) {
+		return 0
+	}
+	meters := r.GetWidth()
+
+	// Bicycles ride over the widest road on the left or the right
+	if direction == "BICYCLE" {
+		var crossingMeters float64
+		for _, name := range []string{collisonwarning.GetDirectionLeft(roadName), collisonwarning.GetDirectionRight(roadName)} {
+			crossing := i.GetRoadByName(name)
+			if crossing != nil {
+				crossingMeters = max(crossingMeters, crossing.GetWidth())
+			}
+		}
+		if crossingMeters > 0 {
+			meters = crossingMeters
+		}
+	}
+	return road.GetCrossingTime(direction, meters)
+}
+
+func (i *Intersection) GetMetrics() metrics.Summary {
+	return road.GetMetricsOfRoads(i.roads)
+}
+
+// GetODMatrix returns the vehicles that crossed by origin and destination road
+func (i *Intersection) GetODMatrix() *metrics.ODMatrix {
+	return i.routes
+}
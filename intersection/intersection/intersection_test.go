@@ -0,0 +1,260 @@
+package intersection
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+func prepareClearanceIntersection(names ...string) *Intersection {
+	var roads []*road.Road
+	for _, name := range names {
+		roads = append(roads, road.NewRoad(road.NewRoadInput{Name: name, Forward: 1, SignalTiming: road.SignalTiming{AllRed: 2}}))
+	}
+	in := NewIntersection(roads)
+	in.FullStopLights()
+	in.Tick(0)
+	return in
+}
+
+// stopNorth lets NORTH go and turns it RED again on tick 3
+func stopNorth(t *testing.T, in *Intersection) {
+	in.Tick(2)
+	for _, state := range []road.LightState{"GREEN", "ORANGE"} {
+		if err := in.SetLights("NORTH", "FORWARD", state); err != nil {
+			t.Fatalf(`NORTH should turn %s, got %v`, state, err)
+		}
+	}
+	in.Tick(3)
+	if err := in.SetLights("NORTH", "FORWARD", "RED"); err != nil {
+		t.Fatalf(`NORTH should turn RED, got %v`, err)
+	}
+}
+
+func TestAllRedClearance(t *testing.T) {
+	in := prepareClearanceIntersection("NORTH", "EAST")
+	if err := in.SetLights("NORTH", "FORWARD", "GREEN"); err == nil {
+		t.Fatalf(`NORTH should wait for the all red time after the start`)
+	}
+	stopNorth(t, in)
+
+	// EAST crosses NORTH and waits for the all red time
+	if err := in.SetLights("EAST", "FORWARD", "GREEN"); err == nil {
+		t.Fatalf(`EAST should wait for the all red time`)
+	}
+	in.Tick(5)
+	if err := in.SetLights("EAST", "FORWARD", "GREEN"); err != nil {
+		t.Fatalf(`EAST should turn GREEN after the all red time, got %v`, err)
+	}
+}
+
+func TestAllRedClearanceOnlyForConflicts(t *testing.T) {
+	in := prepareClearanceIntersection("NORTH", "SOUTH")
+	stopNorth(t, in)
+
+	// SOUTH doesn't cross NORTH
+	if err := in.SetLights("SOUTH", "FORWARD", "GREEN"); err != nil {
+		t.Fatalf(`SOUTH should turn GREEN right away, got %v`, err)
+	}
+	if err := in.SetLights("SOUTH", "FORWARD", "GREN"); err == nil {
+		t.Fatalf(`Unknown states should be refused`)
+	}
+}
+
+func TestPressButton(t *testing.T) {
+	north := road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1, CrossWalkEnabled: true})
+	in := NewIntersection([]*road.Road{north})
+	if err := in.PressButton("NORTH", "FORWARD"); err == nil {
+		t.Fatalf(`Lanes for cars should have no button`)
+	}
+	if err := in.PressButton("NORTH", "BICYCLE"); err == nil {
+		t.Fatalf(`Missing bicycle lane should have no button`)
+	}
+	if err := in.PressButton("NORTH", "CROSSWALK"); err != nil {
+		t.Fatalf(`Crosswalk should have a button, got %v`, err)
+	}
+	if !in.IsCalled("NORTH", "CROSSWALK") {
+		t.Fatalf(`Crosswalk should be called after pressing the button`)
+	}
+}
+
+func TestAllLaneWithOwnLeftLane(t *testing.T) {
+	east := road.NewRoad(road.NewRoadInput{Name: "EAST", Left: 1, All: 1})
+	in := NewIntersection([]*road.Road{east})
+	in.FullStopLights()
+	in.Tick(0)
+	if err := in.SetLights("EAST", "ALL", "GREEN"); err != nil {
+		t.Fatalf(`EAST:ALL should turn GREEN, got %v`, err)
+	}
+
+	// The LEFT lane ends on its own
+	in.Tick(1)
+	for _, state := range []road.LightState{"ORANGE", "RED"} {
+		in.Tick(in.CurrentTick + 1)
+		if err := in.SetLights("EAST", "LEFT", state); err != nil {
+			t.Fatalf(`EAST:LEFT should turn %s, got %v`, state, err)
+		}
+	}
+
+	// The ALL lane can't turn ORANGE while the LEFT lane is RED
+	err := in.SetLights("EAST", "ALL", "ORANGE")
+	if err == nil || !strings.Contains(err.Error(), "EAST:LEFT") {
+		t.Fatalf(`EAST:ALL should not turn ORANGE with EAST:LEFT RED, got %v`, err)
+	}
+	if in.GetLaneState("EAST", "ALL") != "GREEN" || in.GetLaneState("EAST", "LEFT") != "RED" {
+		t.Fatalf(`EAST:ALL should stay GREEN and EAST:LEFT RED, got %s and %s`, in.GetLaneState("EAST", "ALL"), in.GetLaneState("EAST", "LEFT"))
+	}
+}
+
+func TestAddEmergencyVehicle(t *testing.T) {
+	north := road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1, CrossWalkEnabled: true})
+	in := NewIntersection([]*road.Road{north})
+	for _, lane := range []string{"CROSSWALK", "OUTPUT", "LEFT"} {
+		if err := in.AddEmergencyVehicle("NORTH", lane); err == nil {
+			t.Fatalf(`Emergency vehicle should not drive in NORTH:%s`, lane)
+		}
+	}
+	if err := in.AddEmergencyVehicle("NORTH", "FORWARD"); err != nil {
+		t.Fatalf(`Emergency vehicle should drive in NORTH:FORWARD, got %v`, err)
+	}
+	if !in.HasEmergencyVehicle("NORTH", "FORWARD") {
+		t.Fatalf(`NORTH:FORWARD should have an emergency vehicle`)
+	}
+}
+
+func TestAddBus(t *testing.T) {
+	north := road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1, CrossWalkEnabled: true})
+	in := NewIntersection([]*road.Road{north})
+	if err := in.AddBus("NORTH", "CROSSWALK", 10); err == nil {
+		t.Fatalf(`Bus should not drive in NORTH:CROSSWALK`)
+	}
+	if err := in.AddBus("NORTH", "FORWARD", 10); err != nil {
+		t.Fatalf(`Bus should drive in NORTH:FORWARD, got %v`, err)
+	}
+	if !in.HasBus("NORTH", "FORWARD") {
+		t.Fatalf(`NORTH:FORWARD should have a bus`)
+	}
+
+	// The light is on FLASH, so the bus crosses on the next tick
+	in.Tick(1)
+	summary := in.GetMetrics()
+	if in.HasBus("NORTH", "FORWARD") || summary.Buses != 1 || summary.BusAverageDelay != 1 {
+		t.Fatalf(`Bus should cross with a delay of 1 tick, got %d buses with %.1f ticks`, summary.Buses, summary.BusAverageDelay)
+	}
+}
+
+func TestTrafficDrivesToDestination(t *testing.T) {
+	north := road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})
+	south := road.NewRoad(road.NewRoadInput{Name: "SOUTH", Forward: 1})
+	in := NewIntersection([]*road.Road{north, south})
+	in.AddBus("NORTH", "FORWARD", 0)
+
+	// The light is on FLASH, so the bus crosses to the OUTPUT lane of the SOUTH
+	in.Tick(1)
+	if south.GetLongestWaitingTraffic("OUTPUT") != 1 {
+		t.Fatalf(`Bus should be on SOUTH:OUTPUT, got %d vehicles`, south.GetLongestWaitingTraffic("OUTPUT"))
+	}
+	if in.GetODMatrix().Get("NORTH", "SOUTH") != 1 {
+		t.Fatalf(`Bus should be counted from NORTH to SOUTH, got %v`, in.GetODMatrix().GetCounts())
+	}
+}
+
+func TestEventsStayInTheIntersection(t *testing.T) {
+	first := NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	second := NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	var firstEvents, secondEvents []events.BusArrived
+	events.Subscribe(first.GetEvents(), func(e events.BusArrived) { firstEvents = append(firstEvents, e) })
+	events.Subscribe(second.GetEvents(), func(e events.BusArrived) { secondEvents = append(secondEvents, e) })
+
+	first.AddBus("NORTH", "FORWARD", 10)
+	if len(firstEvents) != 1 || firstEvents[0].Lateness != 10 || len(secondEvents) != 0 {
+		t.Fatalf(`Only the first intersection should see the bus, got %v and %v`, firstEvents, secondEvents)
+	}
+
+	// The light of the first intersection changes, the second one doesn't hear about it
+	var lights []events.LightChanged
+	events.Subscribe(second.GetEvents(), func(e events.LightChanged) { lights = append(lights, e) })
+	first.FullStopLights()
+	if len(lights) != 0 {
+		t.Fatalf(`The second intersection should not see the lights of the first one, got %v`, lights)
+	}
+}
+
+func TestViewAndUpdateBetweenTicks(t *testing.T) {
+	in := NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	in.DisableLights()
+
+	// Tick while the API reads and changes the intersection
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for tick := 0; tick < 200; tick++ {
+			in.Tick(tick)
+		}
+	}()
+	for n := 0; n < 200; n++ {
+		in.Update(func() error {
+			return in.AddBus("NORTH", "FORWARD", 0)
+		})
+		in.View(func() {
+			if in.GetWaitingTraffic() < 0 || in.GetMetrics().Vehicles < 0 {
+				t.Errorf(`The intersection should be readable between the ticks`)
+			}
+		})
+	}
+	wait.Wait()
+
+	// A failed update is returned
+	err := in.Update(func() error {
+		return in.AddBus("SOUTH", "FORWARD", 0)
+	})
+	if err == nil {
+		t.Fatalf(`Adding a bus to an unknown road should fail`)
+	}
+}
+
+func TestWatchdogFlashesWithoutController(t *testing.T) {
+	in := NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	in.EnableWatchdog(2)
+	in.FullStopLights()
+
+	// The heartbeat keeps the lights working
+	for tick := 0; tick < 5; tick++ {
+		in.Tick(tick)
+		in.Heartbeat()
+	}
+	if in.IsFailSafe() {
+		t.Fatalf(`The watchdog should not trip with a heartbeat every tick`)
+	}
+
+	// Without a heartbeat all lights FLASH
+	in.Tick(6)
+	in.Tick(7)
+	if !in.IsFailSafe() || in.GetLaneState("NORTH", "FORWARD") != "FLASH" {
+		t.Fatalf(`All lights should FLASH without a heartbeat, got %s`, in.GetLaneState("NORTH", "FORWARD"))
+	}
+	if err := in.SetLights("NORTH", "FORWARD", "RED"); err != ErrWatchdogTripped {
+		t.Fatalf(`Light changes should be refused after the watchdog tripped, got %v`, err)
+	}
+	in.Heartbeat()
+	if !in.IsFailSafe() {
+		t.Fatalf(`A heartbeat should not stop the FLASH`)
+	}
+
+	// The traffic controller takes control again by stopping all lights
+	if !in.FullStopLights() || in.IsFailSafe() {
+		t.Fatalf(`Stopping all lights should reset the watchdog`)
+	}
+	if err := in.SetLights("NORTH", "FORWARD", "GREEN"); err != nil {
+		t.Fatalf(`Light changes should work again, got %v`, err)
+	}
+	in.Tick(8)
+	in.Tick(9)
+	if in.IsFailSafe() {
+		t.Fatalf(`A light change should count as a heartbeat`)
+	}
+}
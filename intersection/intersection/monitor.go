@@ -0,0 +1,84 @@
+package intersection
+
+import (
+	"errors"
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+// Fault is a conflict the conflict monitor found
+type Fault struct {
+	Tick         int    `json:"tick"`
+	Road         string `json:"road"`
+	Lane         string `json:"lane"`
+	ConflictRoad string `json:"conflictRoad"`
+	ConflictLane string `json:"conflictLane"`
+}
+
+var ErrConflictMonitorTripped = errors.New("conflict monitor tripped, all lights FLASH until it is reset")
+
+// ConflictMonitor watches the lights of the intersection, apart from the traffic controller.
+// When a light change would make two lanes that cross GREEN or ORANGE at the same time, it puts
+// all lights on FLASH instead and refuses new light changes until it is reset.
+type ConflictMonitor struct {
+	tripped bool
+	faults  []Fault
+}
+
+func NewConflictMonitor() *ConflictMonitor {
+	return &ConflictMonitor{faults: []Fault{}}
+}
+
+func (m *ConflictMonitor) IsTripped() bool {
+	return m.tripped
+}
+
+// GetFaults returns a copy of the faults, the monitor keeps adding to them
+func (m *ConflictMonitor) GetFaults() []Fault {
+	return append([]Fault{}, m.faults...)
+}
+
+// Check looks for lanes that cross and would both be GREEN or ORANGE when the proposed lanes change
+// to their new state, returns true when it tripped. The lights themselves don't change.
+func (m *ConflictMonitor) Check(currentTick int, roads []*road.Road, proposed map[*road.Lane]road.LightState) bool {
+	// Find all the lanes that would let traffic go
+	var active []collisonwarning.Movement
+	for _, r := range roads {
+		for _, l := range r.GetLanes() {
+			if l.GetDirection() == "OUTPUT" {
+				continue
+			}
+			state, ok := proposed[l]
+			if !ok {
+				state = l.GetState()
+			}
+			if state == road.STATE_GREEN || state == road.STATE_ORANGE {
+				active = append(active, collisonwarning.Movement{Road: r.GetName(), Lane: l.GetDirection()})
+			}
+		}
+	}
+
+	// Check every pair
+	for index, a := range active {
+		for _, b := range active[index+1:] {
+			if !collisonwarning.HasConflict(a, b) {
+				continue
+			}
+
+			// Remember the fault
+			fault := Fault{Tick: currentTick, Road: a.Road, Lane: a.Lane, ConflictRoad: b.Road, ConflictLane: b.Lane}
+			m.faults = append(m.faults, fault)
+			m.tripped = true
+			log.Default().Println("CM: Conflict between", a.Road, ":", a.Lane, "and", b.Road, ":", b.Lane, "on tick", currentTick, ", all lights FLASH")
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ConflictMonitor) Reset() {
+	log.Default().Println("CM: Reset")
+	m.tripped = false
+}
@@ -0,0 +1,78 @@
+package intersection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+func prepareIntersection() *Intersection {
+	return NewIntersection([]*road.Road{
+		road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1}),
+		road.NewRoad(road.NewRoadInput{Name: "EAST", Forward: 1}),
+		road.NewRoad(road.NewRoadInput{Name: "SOUTH", Forward: 1}),
+	})
+}
+
+func TestConflictMonitorAllowsSafeLights(t *testing.T) {
+	in := prepareIntersection()
+	in.FullStopLights()
+	if in.SetLights("NORTH", "FORWARD", "GREEN") != nil || in.SetLights("SOUTH", "FORWARD", "GREEN") != nil {
+		t.Fatalf(`NORTH and SOUTH going forward should be allowed together`)
+	}
+	if in.IsFaulted() {
+		t.Fatalf(`Conflict monitor should not trip, got faults %v`, in.GetFaults())
+	}
+}
+
+func TestConflictMonitorForcesFlash(t *testing.T) {
+	in := prepareIntersection()
+	in.FullStopLights()
+	in.SetLights("NORTH", "FORWARD", "GREEN")
+	var changes []events.LightChanged
+	events.Subscribe(in.GetEvents(), func(e events.LightChanged) { changes = append(changes, e) })
+
+	// Crossing traffic trips the monitor
+	if !errors.Is(in.SetLights("EAST", "FORWARD", "GREEN"), ErrConflictMonitorTripped) {
+		t.Fatalf(`SetLights should fail on a conflict`)
+	}
+	for _, change := range changes {
+		if change.State == "GREEN" {
+			t.Fatalf(`%s:%s should never turn GREEN, the conflict has to be found first`, change.Road, change.Lane)
+		}
+	}
+	if !in.IsFaulted() {
+		t.Fatalf(`Conflict monitor should trip`)
+	}
+	faults := in.GetFaults()
+	if len(faults) != 1 || faults[0].Road != "NORTH" || faults[0].ConflictRoad != "EAST" {
+		t.Fatalf(`Fault should be between NORTH and EAST, got %v`, faults)
+	}
+	for _, name := range []string{"NORTH", "EAST", "SOUTH"} {
+		if in.GetLaneState(name, "FORWARD") != "FLASH" {
+			t.Fatalf(`%s should be FLASH, got %s`, name, in.GetLaneState(name, "FORWARD"))
+		}
+	}
+
+	// New commands are refused
+	if in.FullStopLights() || in.SetLights("SOUTH", "FORWARD", "RED") == nil || in.EnableLights("SOUTH", "FORWARD") {
+		t.Fatalf(`Light changes should be refused after a fault`)
+	}
+	if in.GetLaneState("SOUTH", "FORWARD") != "FLASH" {
+		t.Fatalf(`SOUTH should stay FLASH, got %s`, in.GetLaneState("SOUTH", "FORWARD"))
+	}
+
+	// A reset makes everything RED again
+	in.ResetFaults()
+	if in.IsFaulted() || in.GetLaneState("NORTH", "FORWARD") != "RED" {
+		t.Fatalf(`Reset should make the lights RED, got %s`, in.GetLaneState("NORTH", "FORWARD"))
+	}
+	if in.SetLights("EAST", "FORWARD", "GREEN") != nil {
+		t.Fatalf(`SetLights should work after a reset`)
+	}
+	if len(in.GetFaults()) != 1 {
+		t.Fatalf(`Faults should be kept after a reset, got %v`, in.GetFaults())
+	}
+}
@@ -0,0 +1,54 @@
+package intersection
+
+import (
+	"errors"
+	"log"
+)
+
+const DEFAULT_WATCHDOG_TIMEOUT int = 5
+
+var ErrWatchdogTripped = errors.New("lost the traffic controller, all lights FLASH until they are stopped")
+
+// Watchdog watches the traffic controller that runs apart from the intersection. Without it a traffic controller
+// that died keeps the last lights forever. When no command or heartbeat arrived for the timeout, the lights
+// FLASH and new light changes are refused until the traffic controller stops all lights again.
+type Watchdog struct {
+	timeout       int // Ticks without a command or heartbeat before the lights FLASH
+	lastHeartbeat int // Tick of the last command or heartbeat
+	tripped       bool
+}
+
+func NewWatchdog(timeout int, currentTick int) *Watchdog {
+	if timeout <= 0 {
+		timeout = DEFAULT_WATCHDOG_TIMEOUT
+	}
+	return &Watchdog{timeout: timeout, lastHeartbeat: currentTick}
+}
+
+func (w *Watchdog) IsTripped() bool {
+	return w.tripped
+}
+
+// Heartbeat tells the watchdog the traffic controller is still there
+func (w *Watchdog) Heartbeat(currentTick int) {
+	w.lastHeartbeat = max(w.lastHeartbeat, currentTick)
+}
+
+// Check looks at the time since the last heartbeat, returns true when it tripped
+func (w *Watchdog) Check(currentTick int) bool {
+	if w.tripped || currentTick-w.lastHeartbeat <= w.timeout {
+		return false
+	}
+	w.tripped = true
+	log.Default().Println("WD: No command or heartbeat of the traffic controller since tick", w.lastHeartbeat, ", all lights FLASH")
+	return true
+}
+
+// Reset accepts new light changes again, the traffic controller took control of the lights
+func (w *Watchdog) Reset(currentTick int) {
+	if w.tripped {
+		log.Default().Println("WD: Reset, the traffic controller is back")
+	}
+	w.tripped = false
+	w.Heartbeat(currentTick)
+}
@@ -1,27 +1,100 @@
 package intersectionapi
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
 	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
 )
 
 var GLOBAL_INTERSECTION *intersection.Intersection = nil
 
-func StartApi(in *intersection.Intersection) {
+// GET /events returns at most this amount of the latest events
+const MAX_RECENT_EVENTS int = 100
+
+// RecentEvent is an event of the intersection with the name of its type, like LightChanged
+type RecentEvent struct {
+	Type  string `json:"type"`
+	Event any    `json:"event"`
+}
+
+// errLaneNotFound is returned by an update for a lane that doesn't exist, the API returns 404
+var errLaneNotFound = errors.New("lane not found")
+
+// Api keeps what the API learns from the events of the intersection and the heartbeats of the traffic controller
+type Api struct {
+	in  *intersection.Intersection
+	hub *streamHub // Clients of GET /api/v1/stream
+
+	// Latest events of the intersection for GET /events
+	recentEvents      []RecentEvent
+	recentEventsMutex sync.Mutex
+
+	// Last heartbeat of the traffic controller, written in an Update and read in a View, the intersection guards it
+	lastController *ControllerData
+
+	// Events of the intersection the API listens to
+	subscriptions []*events.Subscription
+}
+
+// NewApi listens to the events of the intersection, Close stops it
+func NewApi(in *intersection.Intersection) *Api {
+	a := &Api{in: in, hub: newStreamHub(in)}
+	a.subscriptions = append(a.subscriptions, events.SubscribeAll(in.GetEvents(), a.recordEvent))
+	a.subscriptions = append(a.subscriptions, events.SubscribeAll(in.GetEvents(), a.hub.onEvent))
+	return a
+}
+
+// Close stops listening to the events of the intersection
+func (a *Api) Close() {
+	for _, subscription := range a.subscriptions {
+		subscription.Unsubscribe()
+	}
+	a.subscriptions = nil
+}
+
+func StartApi(in *intersection.Intersection, address string) {
 	// Save the intersection
 	GLOBAL_INTERSECTION = in
 
+	// Remember the latest events of the intersection, and stream them
+	a := NewApi(in)
+	defer a.Close()
+
 	// Create the API
+	a.newRouter().Run(address)
+}
+
+func (a *Api) newRouter() *gin.Engine {
 	router := gin.Default()
 	router.GET("/", outputIntersection)
 	router.POST("/stop", setFullStop)
 	router.GET("/road/lane", getLane)
+	router.GET("/statistics", getStatistics)
+	router.GET("/metrics", a.getPrometheusMetrics)
 	router.GET("/road/lane/state", validateNewLaneState)
 	router.POST("/road/lane/state", setNewLaneState)
-	router.Run("localhost:8080")
+	router.POST("/road/lane/button", pressButton)
+	router.POST("/road/lane/emergency", addEmergencyVehicle)
+	router.POST("/road/lane/bus", addBus)
+	router.GET("/events", a.getEvents)
+	router.GET("/faults", getFaults)
+	router.POST("/faults/reset", resetFaults)
+	router.POST("/heartbeat", a.heartbeat)
+
+	// The versioned API, see openapi.json
+	a.addRoutesV1(router)
+	return router
 }
 
 func getLane(c *gin.Context) {
@@ -29,35 +102,201 @@ func getLane(c *gin.Context) {
 	roadName := c.Query("road")
 	laneName := c.Query("lane")
 
-	// Check if lane exists
-	if !GLOBAL_INTERSECTION.HasLane(roadName, laneName) {
+	// Build the JSON
+	type outputData struct {
+		RoadName     string `json:"road"`
+		LaneName     string `json:"lane"`
+		State        string `json:"state"`
+		Signal       string `json:"signal"` // WALK or DONT-WALK for a crosswalk, the state for other lanes
+		Traffic      int    `json:"traffic"`
+		Arrived      int    `json:"arrived"`      // Traffic that arrived in the lane since the start
+		Called       bool   `json:"called"`       // Whether or not somebody waits or pressed the button
+		CrossingTime int    `json:"crossingTime"` // Ticks to cross for a crosswalk or bicycle lane
+		Emergency    bool   `json:"emergency"`    // Whether or not an emergency vehicle waits in the lane
+		Bus          bool   `json:"bus"`          // Whether or not a bus waits in the lane
+	}
+	var r []outputData
+	GLOBAL_INTERSECTION.View(func() {
+		// Check if lane exists
+		if !GLOBAL_INTERSECTION.HasLane(roadName, laneName) {
+			return
+		}
+		for _, lane := range GLOBAL_INTERSECTION.GetRoadByName(roadName).GetLanesByName(laneName) {
+			r = append(r, outputData{
+				RoadName:     roadName,
+				LaneName:     laneName,
+				State:        string(lane.GetState()),
+				Signal:       lane.GetSignal(),
+				Traffic:      lane.GetWaitingTrafficCount(),
+				Arrived:      lane.GetArrivedTrafficCount(),
+				Called:       lane.IsCalled(),
+				CrossingTime: GLOBAL_INTERSECTION.GetCrossingTime(roadName, laneName),
+				Emergency:    lane.HasEmergencyVehicle(),
+				Bus:          lane.HasBus(),
+			})
+		}
+	})
+	if len(r) == 0 {
 		c.AbortWithStatus(404)
 		return
 	}
 
+	// Return the JSON
+	c.JSON(200, r)
+}
+
+func getStatistics(c *gin.Context) {
 	// Build the JSON
-	type outputData struct {
-		RoadName string `json:"road"`
-		LaneName string `json:"lane"`
-		State    string `json:"state"`
-		Traffic  int    `json:"traffic"`
+	type laneData struct {
+		LaneName string          `json:"lane"`
+		Metrics  metrics.Summary `json:"metrics"`
 	}
-	road := GLOBAL_INTERSECTION.GetRoadByName(roadName)
-	lane := road.GetLanesByName(laneName)
-	var r []outputData
-	for _, lane := range lane {
-		r = append(r, outputData{
-			RoadName: roadName,
-			LaneName: laneName,
-			State:    lane.GetState(),
-			Traffic:  lane.GetWaitingTrafficCount(),
-		})
+	type roadData struct {
+		RoadName string          `json:"road"`
+		Metrics  metrics.Summary `json:"metrics"`
+		Lanes    []laneData      `json:"lanes"`
+	}
+	type outputData struct {
+		CurrentTick       int                       `json:"currentTick"`
+		Metrics           metrics.Summary           `json:"metrics"`
+		Roads             []roadData                `json:"roads"`
+		OriginDestination map[string]map[string]int `json:"originDestination"` // Vehicles that crossed by origin and destination road
 	}
+	var r outputData
+	GLOBAL_INTERSECTION.View(func() {
+		r = outputData{
+			CurrentTick:       GLOBAL_INTERSECTION.CurrentTick,
+			Metrics:           GLOBAL_INTERSECTION.GetMetrics(),
+			Roads:             []roadData{},
+			OriginDestination: GLOBAL_INTERSECTION.GetODMatrix().GetCounts(),
+		}
+		for _, road := range GLOBAL_INTERSECTION.GetRoads() {
+			rd := roadData{RoadName: road.GetName(), Metrics: road.GetMetrics(), Lanes: []laneData{}}
+			for _, lane := range road.GetLanes() {
+				if lane.GetDirection() == "OUTPUT" {
+					continue
+				}
+				rd.Lanes = append(rd.Lanes, laneData{LaneName: lane.GetDirection(), Metrics: lane.GetMetrics()})
+			}
+			r.Roads = append(r.Roads, rd)
+		}
+	})
 
 	// Return the JSON
 	c.JSON(200, r)
 }
 
+func (a *Api) getPrometheusMetrics(c *gin.Context) {
+	w := &metrics.PrometheusWriter{}
+
+	// Read everything of the same tick
+	a.in.View(func() {
+		w.Family("intersection_current_tick", "gauge", "Current tick of the simulation.")
+		w.Sample("intersection_current_tick", nil, float64(a.in.CurrentTick))
+
+		// Lanes with the same direction are told apart by their index in the road
+		type laneLabels struct {
+			lane   *road.Lane
+			labels metrics.Labels
+		}
+		var lanes []laneLabels
+		for _, r := range a.in.GetRoads() {
+			for index, lane := range r.GetLanes() {
+				if lane.GetDirection() == "OUTPUT" {
+					continue
+				}
+				lanes = append(lanes, laneLabels{lane: lane, labels: metrics.Labels{
+					{"road", r.GetName()},
+					{"lane", lane.GetDirection()},
+					{"index", strconv.Itoa(index)},
+				}})
+			}
+		}
+
+		w.Family("intersection_lane_queue_length", "gauge", "Vehicles waiting in the lane.")
+		for _, l := range lanes {
+			w.Sample("intersection_lane_queue_length", l.labels, float64(l.lane.GetWaitingTrafficCount()))
+		}
+
+		w.Family("intersection_lane_light_state", "gauge", "Current state of the traffic light, 1 for the active state.")
+		for _, l := range lanes {
+			for _, state := range road.LIGHT_STATES {
+				value := 0.0
+				if l.lane.GetState() == state {
+					value = 1
+				}
+				w.Sample("intersection_lane_light_state", l.labels.With("state", string(state)), value)
+			}
+		}
+
+		w.Family("intersection_lane_vehicles_arrived_total", "counter", "Vehicles that arrived in the lane.")
+		for _, l := range lanes {
+			w.Sample("intersection_lane_vehicles_arrived_total", l.labels, float64(l.lane.GetMetrics().Arrived))
+		}
+
+		w.Family("intersection_lane_vehicles_departed_total", "counter", "Vehicles that crossed the intersection from the lane.")
+		for _, l := range lanes {
+			w.Sample("intersection_lane_vehicles_departed_total", l.labels, float64(l.lane.GetMetrics().Vehicles))
+		}
+
+		w.Family("intersection_lane_wait_ticks", "histogram", "Ticks a vehicle waited before it crossed.")
+		for _, l := range lanes {
+			w.Histogram("intersection_lane_wait_ticks", l.labels, l.lane.GetDelayHistogram())
+		}
+
+		w.Family("intersection_route_vehicles_total", "counter", "Vehicles that crossed from the origin road to the destination road.")
+		for _, origin := range collisonwarning.MATRIX_ROADS {
+			for _, destination := range collisonwarning.MATRIX_ROADS {
+				if origin != destination {
+					w.Sample("intersection_route_vehicles_total", metrics.Labels{{"origin", origin}, {"destination", destination}}, float64(a.in.GetODMatrix().Get(origin, destination)))
+				}
+			}
+		}
+
+		summary := a.in.GetMetrics()
+		w.Family("intersection_buses_departed_total", "counter", "Buses that crossed the intersection.")
+		w.Sample("intersection_buses_departed_total", nil, float64(summary.Buses))
+		w.Family("intersection_bus_wait_ticks_average", "gauge", "Average ticks a bus waited before it crossed.")
+		w.Sample("intersection_bus_wait_ticks_average", nil, summary.BusAverageDelay)
+
+		// The traffic controller sends its counters with the heartbeat
+		var counters metrics.ControllerCounters
+		if a.lastController != nil {
+			counters = a.lastController.Counters
+		}
+		w.Family("trafficcontroller_pattern_steps_total", "counter", "Steps the traffic controller took in the traffic pattern.")
+		w.Sample("trafficcontroller_pattern_steps_total", nil, float64(counters.PatternSteps))
+		w.Family("trafficcontroller_pending_call_overrides_total", "counter", "Pending calls that were handled before the traffic pattern.")
+		w.Sample("trafficcontroller_pending_call_overrides_total", nil, float64(counters.PendingCallOverrides))
+		w.Family("trafficcontroller_collision_warnings_total", "counter", "Green lights that were refused because of a collision warning.")
+		w.Sample("trafficcontroller_collision_warnings_total", nil, float64(counters.CollisionWarnings))
+		w.Family("trafficcontroller_preemptions_total", "counter", "Preemptions of the lights for an emergency vehicle.")
+		w.Sample("trafficcontroller_preemptions_total", nil, float64(counters.Preemptions))
+		w.Family("trafficcontroller_preemption_delay_ticks_total", "counter", "Ticks the traffic on the other lanes waited during the preemptions.")
+		w.Sample("trafficcontroller_preemption_delay_ticks_total", nil, float64(counters.PreemptionDelay))
+		w.Family("trafficcontroller_transit_priority_ticks_total", "counter", "Ticks a GREEN light was extended or shortened for a late bus.")
+		w.Sample("trafficcontroller_transit_priority_ticks_total", metrics.Labels{{"action", "extend"}}, float64(counters.TransitExtensions))
+		w.Sample("trafficcontroller_transit_priority_ticks_total", metrics.Labels{{"action", "reduce"}}, float64(counters.TransitReductions))
+
+		w.Family("intersection_conflict_monitor_faults_total", "counter", "Conflicts the conflict monitor found.")
+		w.Sample("intersection_conflict_monitor_faults_total", nil, float64(len(a.in.GetFaults())))
+		w.Family("intersection_conflict_monitor_tripped", "gauge", "1 when the conflict monitor keeps all lights on FLASH.")
+		tripped := 0.0
+		if a.in.IsFaulted() {
+			tripped = 1
+		}
+		w.Sample("intersection_conflict_monitor_tripped", nil, tripped)
+		w.Family("intersection_watchdog_tripped", "gauge", "1 when the watchdog lost the traffic controller and all lights FLASH.")
+		failSafe := 0.0
+		if a.in.IsFailSafe() {
+			failSafe = 1
+		}
+		w.Sample("intersection_watchdog_tripped", nil, failSafe)
+	})
+
+	c.Data(200, "text/plain; version=0.0.4; charset=utf-8", []byte(w.String()))
+}
+
 func setNewLaneState(c *gin.Context) {
 	// Retrieve details
 	type inputData struct {
@@ -75,14 +314,119 @@ func setNewLaneState(c *gin.Context) {
 	laneName := input.Lane
 	state := input.State
 
-	// Check if lane exists
-	if !GLOBAL_INTERSECTION.HasLane(roadName, laneName) {
+	// Set the state between two ticks, illegal changes are refused with the reason
+	err := GLOBAL_INTERSECTION.Update(func() error {
+		// Check if lane exists
+		if !GLOBAL_INTERSECTION.HasLane(roadName, laneName) {
+			return errLaneNotFound
+		}
+		return GLOBAL_INTERSECTION.SetLights(roadName, laneName, road.LightState(state))
+	})
+	if errors.Is(err, errLaneNotFound) {
 		c.AbortWithStatus(404)
 		return
 	}
+	if err != nil {
+		c.AbortWithStatusJSON(409, gin.H{"reason": err.Error()})
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func pressButton(c *gin.Context) {
+	// Retrieve details
+	type inputData struct {
+		Road string `json:"road"`
+		Lane string `json:"lane"`
+	}
+	var input inputData
+
+	// Call BindJSON to bind the received JSON to
+	if err := c.BindJSON(&input); err != nil {
+		return
+	}
 
-	// Set the state
-	GLOBAL_INTERSECTION.SetLights(roadName, laneName, state)
+	// Register the call between two ticks
+	err := GLOBAL_INTERSECTION.Update(func() error {
+		// Check if lane exists
+		if !GLOBAL_INTERSECTION.HasLane(input.Road, input.Lane) {
+			return errLaneNotFound
+		}
+		return GLOBAL_INTERSECTION.PressButton(input.Road, input.Lane)
+	})
+	if errors.Is(err, errLaneNotFound) {
+		c.AbortWithStatus(404)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(400, gin.H{"reason": err.Error()})
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func addEmergencyVehicle(c *gin.Context) {
+	// Retrieve details
+	type inputData struct {
+		Road string `json:"road"`
+		Lane string `json:"lane"`
+	}
+	var input inputData
+
+	// Call BindJSON to bind the received JSON to
+	if err := c.BindJSON(&input); err != nil {
+		return
+	}
+
+	// Let the emergency vehicle arrive between two ticks, the traffic controller preempts the lights
+	err := GLOBAL_INTERSECTION.Update(func() error {
+		// Check if lane exists
+		if !GLOBAL_INTERSECTION.HasLane(input.Road, input.Lane) {
+			return errLaneNotFound
+		}
+		return GLOBAL_INTERSECTION.AddEmergencyVehicle(input.Road, input.Lane)
+	})
+	if errors.Is(err, errLaneNotFound) {
+		c.AbortWithStatus(404)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(400, gin.H{"reason": err.Error()})
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func addBus(c *gin.Context) {
+	// Retrieve details
+	type inputData struct {
+		Road     string `json:"road"`
+		Lane     string `json:"lane"`
+		Lateness int    `json:"lateness"` // Ticks the bus is behind its schedule
+	}
+	var input inputData
+
+	// Call BindJSON to bind the received JSON to
+	if err := c.BindJSON(&input); err != nil {
+		return
+	}
+
+	// Let the bus arrive between two ticks, the traffic controller gives priority when it is late
+	err := GLOBAL_INTERSECTION.Update(func() error {
+		// Check if lane exists
+		if !GLOBAL_INTERSECTION.HasLane(input.Road, input.Lane) {
+			return errLaneNotFound
+		}
+		return GLOBAL_INTERSECTION.AddBus(input.Road, input.Lane, input.Lateness)
+	})
+	if errors.Is(err, errLaneNotFound) {
+		c.AbortWithStatus(404)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(400, gin.H{"reason": err.Error()})
+		return
+	}
 	c.AbortWithStatus(200)
 }
 
@@ -91,30 +435,142 @@ func validateNewLaneState(c *gin.Context) {
 	roadName := c.Query("road")
 	laneName := c.Query("lane")
 
-	// Check if lane exists
-	if !GLOBAL_INTERSECTION.HasLane(roadName, laneName) {
+	// Build the JSON
+	found := false
+	collision := false
+	GLOBAL_INTERSECTION.View(func() {
+		// Check if lane exists
+		found = GLOBAL_INTERSECTION.HasLane(roadName, laneName)
+		if found {
+			collision = collisonwarning.CollisionWarningOnGreen(GLOBAL_INTERSECTION, roadName, laneName)
+		}
+	})
+	if !found {
 		c.AbortWithStatus(404)
 		return
 	}
 
-	// Build the JSON
-	collision := collisonwarning.CollisionWarningOnGreen(GLOBAL_INTERSECTION, roadName, laneName)
-
 	// Return the result
 	c.JSON(200, collision)
 }
 
 func setFullStop(c *gin.Context) {
-	GLOBAL_INTERSECTION.FullStopLights()
+	err := GLOBAL_INTERSECTION.Update(func() error {
+		if !GLOBAL_INTERSECTION.FullStopLights() {
+			return intersection.ErrConflictMonitorTripped
+		}
+		return nil
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(409, gin.H{"reason": intersection.ErrConflictMonitorTripped.Error()})
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func (a *Api) recordEvent(e any) {
+	// Every tick would push out the other events
+	if _, ok := e.(events.Ticked); ok {
+		return
+	}
+
+	a.recentEventsMutex.Lock()
+	defer a.recentEventsMutex.Unlock()
+	a.recentEvents = append(a.recentEvents, RecentEvent{Type: events.GetName(e), Event: e})
+	if len(a.recentEvents) > MAX_RECENT_EVENTS {
+		a.recentEvents = a.recentEvents[len(a.recentEvents)-MAX_RECENT_EVENTS:]
+	}
+}
+
+func (a *Api) getEvents(c *gin.Context) {
+	// Only the events of one type, like ?type=LightChanged
+	eventType := c.Query("type")
+
+	// Copy the events, the intersection keeps adding them
+	a.recentEventsMutex.Lock()
+	r := []RecentEvent{}
+	for _, e := range a.recentEvents {
+		if eventType == "" || e.Type == eventType {
+			r = append(r, e)
+		}
+	}
+	a.recentEventsMutex.Unlock()
+
+	// Return the JSON
+	c.JSON(200, r)
+}
+
+func getFaults(c *gin.Context) {
+	// Build the JSON
+	type outputData struct {
+		Faulted bool                 `json:"faulted"`
+		Faults  []intersection.Fault `json:"faults"`
+	}
+	var r outputData
+	GLOBAL_INTERSECTION.View(func() {
+		r = outputData{
+			Faulted: GLOBAL_INTERSECTION.IsFaulted(),
+			Faults:  GLOBAL_INTERSECTION.GetFaults(),
+		}
+	})
+
+	// Return the JSON
+	c.JSON(200, r)
+}
+
+func resetFaults(c *gin.Context) {
+	GLOBAL_INTERSECTION.Update(func() error {
+		GLOBAL_INTERSECTION.ResetFaults()
+		return nil
+	})
 	c.AbortWithStatus(200)
 }
 
+func (a *Api) heartbeat(c *gin.Context) {
+	// The traffic controller sends what it does, the body can be empty
+	var controller ControllerData
+	err := json.NewDecoder(c.Request.Body).Decode(&controller.ControllerStatus)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(400, gin.H{"reason": err.Error()})
+		return
+	}
+
+	// Build the JSON
+	type outputData struct {
+		CurrentTick int  `json:"currentTick"`
+		FailSafe    bool `json:"failSafe"` // Whether or not the watchdog lost the traffic controller, stop all lights to take control again
+	}
+	var r outputData
+	a.in.Update(func() error {
+		a.in.Heartbeat()
+		controller.LastHeartbeat = a.in.CurrentTick
+		if controller.Calls == nil {
+			controller.Calls = []trafficcontroller.CallStatus{}
+		}
+		a.lastController = &controller
+		r = outputData{CurrentTick: a.in.CurrentTick, FailSafe: a.in.IsFailSafe()}
+		return nil
+	})
+
+	// Return the JSON
+	c.JSON(200, r)
+}
+
 func outputIntersection(c *gin.Context) {
+	// Show the intersection of a single tick
+	var output string
+	GLOBAL_INTERSECTION.View(func() {
+		output = renderIntersection()
+	})
+	c.Data(200, "text/html; charset=utf-8", []byte(output))
+}
+
+func renderIntersection() string {
 	// This will output HTML
 	output := "<html><head><title>GO Intersection</title></head><body>"
 	output += "<h1>GO Intersection</h1>"
-	output += "<p>Current tick: " + fmt.Sprint(GLOBAL_INTERSECTION.CurrentTick) + "</p>"
-	output += "<p>Total cars waiting: " + fmt.Sprint(GLOBAL_INTERSECTION.GetWaitingTraffic()) + "</p>"
+	output += "<p>Current tick: <span id='tick'>" + fmt.Sprint(GLOBAL_INTERSECTION.CurrentTick) + "</span></p>"
+	output += "<p>Total cars waiting: <span id='waiting'>" + fmt.Sprint(GLOBAL_INTERSECTION.GetWaitingTraffic()) + "</span></p>"
 	output += "<table style='width: 100%'>"
 	output += "<thead>"
 	output += "<tr>"
@@ -128,7 +584,7 @@ func outputIntersection(c *gin.Context) {
 	output += "</thead>"
 	output += "<tbody>"
 	for _, road := range GLOBAL_INTERSECTION.GetRoads() {
-		for _, lane := range road.GetLanes() {
+		for index, lane := range road.GetLanes() {
 			// Figure out if we want to see this line
 			opacity := "1"
 			if lane.GetWaitingTrafficCount() == 0 {
@@ -136,7 +592,7 @@ func outputIntersection(c *gin.Context) {
 			}
 
 			// Create the table row
-			output += "<tr style='opacity: " + opacity + "'>"
+			output += "<tr id='" + road.GetName() + ":" + fmt.Sprint(index) + "' style='opacity: " + opacity + "'>"
 			output += "<td>" + road.GetName() + "</td>"
 			output += "<td>" + lane.GetDirection() + "</td>"
 			if lane.GetDirection() == "OUTPUT" {
@@ -154,23 +610,44 @@ func outputIntersection(c *gin.Context) {
 				// Add notified
 				output += "<td>" + fmt.Sprint(lane.GetNotified()) + "</td>"
 
-				// Find traffic light color
-				lightColor := "orange"
-				if lane.GetState() == "GREEN" {
-					lightColor = "green"
-				} else if lane.GetState() == "RED" {
-					lightColor = "red"
-				}
-
 				// Add traffic light
-				output += "<td style='color: " + lightColor + "'>" + lane.GetState() + "</td>"
+				output += "<td style='color: " + getLightColor(string(lane.GetState())) + "'>" + string(lane.GetState()) + "</td>"
 			}
 			output += "</tr>"
 		}
 	}
 	output += "</tbody>"
 	output += "</table>"
-	output += "<script>setTimeout(() => { window.location.reload() }, 2000);</script>"
+
+	// Apply the lanes that changed in every tick of the stream
+	output += "<script>new EventSource('/api/v1/stream').addEventListener('tick', (message) => {"
+	output += "const tick = JSON.parse(message.data);"
+	output += "document.getElementById('tick').textContent = tick.tick;"
+	output += "document.getElementById('waiting').textContent = tick.waiting;"
+	output += "for (const lane of tick.lanes) {"
+	output += "const row = document.getElementById(lane.road + ':' + lane.index);"
+	output += "if (!row) continue;"
+	output += "row.style.opacity = lane.waiting == 0 ? '0.5' : '1';"
+	output += "row.cells[2].textContent = lane.waiting;"
+	output += "row.cells[3].textContent = lane.waitingSince;"
+	output += "row.cells[4].textContent = lane.notified;"
+	output += "row.cells[5].textContent = lane.state;"
+	output += "row.cells[5].style.color = {GREEN: 'green', RED: 'red', OFF: 'gray'}[lane.state] || 'orange';"
+	output += "}"
+	output += "});</script>"
 	output += "</body></html>"
-	c.Data(200, "text/html; charset=utf-8", []byte(output))
+	return output
+}
+
+// getLightColor is the color of the state on the page
+func getLightColor(state string) string {
+	switch state {
+	case "GREEN":
+		return "green"
+	case "RED":
+		return "red"
+	case "OFF":
+		return "gray"
+	}
+	return "orange"
 }
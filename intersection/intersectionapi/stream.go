@@ -0,0 +1,149 @@
+package intersectionapi
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+)
+
+// A client of the stream can be this amount of messages behind, a slower client is disconnected
+const STREAM_BUFFER int = 256
+
+// StreamMessage is a server-sent event of GET /api/v1/stream
+type StreamMessage struct {
+	Event string // snapshot, tick or the type of the event, like LightChanged
+	Data  any
+}
+
+// TickData is sent after every tick, with only the lanes that changed since the last tick
+type TickData struct {
+	Tick     int             `json:"tick"`
+	Waiting  int             `json:"waiting"` // Traffic waiting on all lanes
+	Faulted  bool            `json:"faulted"`
+	FailSafe bool            `json:"failSafe"`
+	Lanes    []LaneDeltaData `json:"lanes"`
+}
+
+// LaneDeltaData is a lane that changed, it replaces the lane with the same road and index
+type LaneDeltaData struct {
+	Road string `json:"road"`
+	LaneData
+}
+
+// streamHub sends the ticks and the events of the intersection to the clients of the stream
+type streamHub struct {
+	in      *intersection.Intersection
+	mutex   sync.Mutex
+	clients map[chan StreamMessage][]string // Event types every client wants, empty for all
+	lanes   map[string]LaneData             // Lanes of the last tick by ROAD:INDEX, to find the changes
+}
+
+// newStreamHub streams the ticks and events of the intersection, subscribe onEvent to its events
+func newStreamHub(in *intersection.Intersection) *streamHub {
+	return &streamHub{in: in, clients: map[chan StreamMessage][]string{}, lanes: map[string]LaneData{}}
+}
+
+// onEvent is called during the tick or an update, the intersection is locked
+func (h *streamHub) onEvent(e any) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if ticked, ok := e.(events.Ticked); ok {
+		h.broadcast(StreamMessage{Event: "tick", Data: h.newTickData(ticked.Tick)})
+		return
+	}
+	h.broadcast(StreamMessage{Event: events.GetName(e), Data: e})
+}
+
+// newTickData finds the lanes that changed since the last tick
+func (h *streamHub) newTickData(tick int) TickData {
+	r := TickData{
+		Tick:     tick,
+		Waiting:  h.in.GetWaitingTraffic(),
+		Faulted:  h.in.IsFaulted(),
+		FailSafe: h.in.IsFailSafe(),
+		Lanes:    []LaneDeltaData{},
+	}
+	for _, rd := range h.in.GetRoads() {
+		for _, lane := range newRoadData(h.in, rd).Lanes {
+			key := fmt.Sprintf("%s:%d", rd.GetName(), lane.Index)
+			if previous, ok := h.lanes[key]; ok && previous == lane {
+				continue
+			}
+			h.lanes[key] = lane
+			r.Lanes = append(r.Lanes, LaneDeltaData{Road: rd.GetName(), LaneData: lane})
+		}
+	}
+	return r
+}
+
+// broadcast never waits for a client, the tick would wait for it
+func (h *streamHub) broadcast(message StreamMessage) {
+	for client, types := range h.clients {
+		if message.Event != "tick" && len(types) > 0 && !slices.Contains(types, message.Event) {
+			continue
+		}
+		select {
+		case client <- message:
+		default:
+			close(client)
+			delete(h.clients, client)
+		}
+	}
+}
+
+func (h *streamHub) subscribe(types []string) chan StreamMessage {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	client := make(chan StreamMessage, STREAM_BUFFER)
+	h.clients[client] = types
+	return client
+}
+
+func (h *streamHub) unsubscribe(client chan StreamMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.clients[client]; ok {
+		close(client)
+		delete(h.clients, client)
+	}
+}
+
+func (a *Api) getStreamV1(c *gin.Context) {
+	// Only the events of some types, like ?type=LightChanged&type=BusArrived, the ticks are always sent
+	types := c.QueryArray("type")
+	if !validateEventTypes(c, types) {
+		return
+	}
+
+	// Listen before the snapshot, so no tick is missed
+	client := a.hub.subscribe(types)
+	defer a.hub.unsubscribe(client)
+
+	// Start with the whole intersection, the ticks only have the lanes that changed
+	var snapshot IntersectionData
+	a.in.View(func() {
+		snapshot = a.newIntersectionData()
+	})
+	c.Header("Cache-Control", "no-cache")
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+
+	// Send the ticks and events until the client leaves, or falls too far behind
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case message, ok := <-client:
+			if !ok {
+				return false
+			}
+			c.SSEvent(message.Event, message.Data)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package intersectionapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+)
+
+// readMessage reads the stream until the next message with the name, returns its data
+func readMessage(t *testing.T, reader *bufio.Reader, name string) string {
+	event := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf(`The stream should send %s, got %v`, name, err)
+		}
+		line = strings.TrimSpace(line)
+		if value, ok := strings.CutPrefix(line, "event:"); ok {
+			event = value
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok && event == name {
+			return value
+		}
+	}
+}
+
+func TestStreamV1(t *testing.T) {
+	router := prepareApi(t)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Follow the stream, only the light changes besides the ticks
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/stream?type=LightChanged", nil)
+	reply, err := http.DefaultClient.Do(request)
+	if err != nil || reply.StatusCode != 200 {
+		t.Fatalf(`The stream should start, got %v`, err)
+	}
+	defer reply.Body.Close()
+	reader := bufio.NewReader(reply.Body)
+
+	// The stream starts with the whole intersection
+	var snapshot IntersectionData
+	json.Unmarshal([]byte(readMessage(t, reader, "snapshot")), &snapshot)
+	if len(snapshot.Roads) != 2 {
+		t.Fatalf(`The snapshot should have the roads, got %+v`, snapshot)
+	}
+
+	// The first tick has every lane, the next tick only the lanes that changed
+	GLOBAL_INTERSECTION.Tick(0)
+	var tick TickData
+	json.Unmarshal([]byte(readMessage(t, reader, "tick")), &tick)
+	if tick.Tick != 0 || len(tick.Lanes) != 4 {
+		t.Fatalf(`The first tick should have all 4 lanes, got %+v`, tick)
+	}
+	GLOBAL_INTERSECTION.Update(func() error {
+		GLOBAL_INTERSECTION.FullStopLights()
+		return nil
+	})
+	var light events.LightChanged
+	json.Unmarshal([]byte(readMessage(t, reader, "LightChanged")), &light)
+	if light.State != "RED" {
+		t.Fatalf(`The light change should be streamed, got %+v`, light)
+	}
+	GLOBAL_INTERSECTION.Tick(1)
+	json.Unmarshal([]byte(readMessage(t, reader, "tick")), &tick)
+	if tick.Tick != 1 || len(tick.Lanes) != 4 || tick.Lanes[0].State != "RED" {
+		t.Fatalf(`The lanes that turned RED should be in the tick, got %+v`, tick)
+	}
+	GLOBAL_INTERSECTION.Tick(2)
+	json.Unmarshal([]byte(readMessage(t, reader, "tick")), &tick)
+	for _, lane := range tick.Lanes {
+		if lane.State != "RED" {
+			t.Fatalf(`Only the lanes that changed should be in the tick, got %+v`, lane)
+		}
+	}
+}
+
+func TestStreamDropsSlowClient(t *testing.T) {
+	h := newStreamHub(nil)
+	client := h.subscribe(nil)
+
+	// The client doesn't read, the events don't wait for it
+	for n := 0; n <= STREAM_BUFFER; n++ {
+		h.onEvent(events.LaneEmptied{Tick: n})
+	}
+	for range client {
+	}
+	if len(h.clients) != 0 {
+		t.Fatalf(`The slow client should be disconnected`)
+	}
+	h.unsubscribe(client)
+}
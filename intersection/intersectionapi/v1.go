@@ -0,0 +1,210 @@
+package intersectionapi
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+// OPENAPI describes the versioned API, GET /api/v1/openapi.json returns it
+//
+//go:embed openapi.json
+var OPENAPI []byte
+
+// ErrorData is the body of every error of the versioned API
+type ErrorData struct {
+	Reason string `json:"reason"`
+}
+
+// IntersectionData is the whole intersection on one tick
+type IntersectionData struct {
+	Name         string          `json:"name"`         // Name of the intersection in a corridor, empty for a single intersection
+	CurrentTick  int             `json:"currentTick"`  // Tick of the intersection
+	Faulted      bool            `json:"faulted"`      // Whether or not the conflict monitor tripped
+	FailSafe     bool            `json:"failSafe"`     // Whether or not the watchdog lost the traffic controller
+	Controller   *ControllerData `json:"controller"`   // What the traffic controller does, null until its first heartbeat
+	PendingCalls []LaneRefData   `json:"pendingCalls"` // Lanes where somebody waits or pressed the button and the light is not GREEN
+	Roads        []RoadData      `json:"roads"`
+}
+
+// ControllerData is what the traffic controller sent with its last heartbeat
+type ControllerData struct {
+	LastHeartbeat int `json:"lastHeartbeat"` // Tick of the intersection the last heartbeat arrived
+	trafficcontroller.ControllerStatus
+}
+
+// LaneRefData is a lane of a road, lanes with the same direction are told apart by the index
+type LaneRefData struct {
+	Road  string `json:"road"`
+	Lane  string `json:"lane"`
+	Index int    `json:"index"`
+}
+
+type RoadData struct {
+	Name    string           `json:"name"`
+	Waiting int              `json:"waiting"` // Traffic waiting on all lanes of the road
+	Lanes   []LaneData       `json:"lanes"`
+	Metrics *metrics.Summary `json:"metrics,omitempty"` // Only in the detail of a road
+}
+
+type LaneData struct {
+	Index        int    `json:"index"`        // Position of the lane in the road, lanes with the same direction are told apart by it
+	Direction    string `json:"direction"`    // LEFT, FORWARD, RIGHT, ALL, CROSSWALK or BICYCLE
+	State        string `json:"state"`        // Light of the lane
+	Signal       string `json:"signal"`       // Signal the humans and bicycles see
+	Waiting      int    `json:"waiting"`      // Traffic in the queue of the lane
+	Called       bool   `json:"called"`       // Whether or not somebody waits or pressed the button
+	CrossingTime int    `json:"crossingTime"` // Ticks humans and bicycles need to cross, 0 for other lanes
+	Emergency    bool   `json:"emergency"`    // Whether or not an emergency vehicle waits in the lane
+	Bus          bool   `json:"bus"`          // Whether or not a bus waits in the lane
+	WaitingSince int    `json:"waitingSince"` // Tick the first traffic in the queue arrived, 0 without traffic
+	Notified     bool   `json:"notified"`     // Whether or not the traffic controller was told about the traffic
+}
+
+func (a *Api) addRoutesV1(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	v1.GET("/intersection", a.getIntersectionV1)
+	v1.GET("/roads/:road", a.getRoadV1)
+	v1.GET("/events", a.getEventsV1)
+	v1.GET("/stream", a.getStreamV1)
+	v1.GET("/openapi.json", getOpenApiV1)
+
+	// Unknown paths of the versioned API get a JSON error too
+	router.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			abortWithReason(c, 404, fmt.Sprintf("%s %s doesn't exist", c.Request.Method, c.Request.URL.Path))
+		}
+	})
+}
+
+func abortWithReason(c *gin.Context, status int, reason string) {
+	c.AbortWithStatusJSON(status, ErrorData{Reason: reason})
+}
+
+func (a *Api) getIntersectionV1(c *gin.Context) {
+	// Build the JSON of a single tick
+	var r IntersectionData
+	a.in.View(func() {
+		r = a.newIntersectionData()
+	})
+
+	// Return the JSON
+	c.JSON(200, r)
+}
+
+// newIntersectionData builds the JSON of the whole intersection, call it in a View
+func (a *Api) newIntersectionData() IntersectionData {
+	r := IntersectionData{
+		Name:         a.in.GetName(),
+		CurrentTick:  a.in.CurrentTick,
+		Faulted:      a.in.IsFaulted(),
+		FailSafe:     a.in.IsFailSafe(),
+		PendingCalls: []LaneRefData{},
+		Roads:        []RoadData{},
+	}
+	if a.lastController != nil {
+		controller := *a.lastController
+		r.Controller = &controller
+	}
+	for _, rd := range a.in.GetRoads() {
+		roadData := newRoadData(a.in, rd)
+		for _, lane := range roadData.Lanes {
+			if lane.Called && lane.State != string(road.STATE_GREEN) {
+				r.PendingCalls = append(r.PendingCalls, LaneRefData{Road: roadData.Name, Lane: lane.Direction, Index: lane.Index})
+			}
+		}
+		r.Roads = append(r.Roads, roadData)
+	}
+	return r
+}
+
+func (a *Api) getRoadV1(c *gin.Context) {
+	roadName := c.Param("road")
+
+	// Build the JSON of a single tick
+	var r *RoadData
+	a.in.View(func() {
+		rd := a.in.GetRoadByName(roadName)
+		if rd == nil {
+			return
+		}
+		roadData := newRoadData(a.in, rd)
+		summary := rd.GetMetrics()
+		roadData.Metrics = &summary
+		r = &roadData
+	})
+	if r == nil {
+		abortWithReason(c, 404, fmt.Sprintf("road %s doesn't exist", roadName))
+		return
+	}
+
+	// Return the JSON
+	c.JSON(200, r)
+}
+
+// newRoadData builds the JSON of a road of the intersection, call it in a View
+func newRoadData(in *intersection.Intersection, rd *road.Road) RoadData {
+	r := RoadData{Name: rd.GetName(), Lanes: []LaneData{}}
+	for index, lane := range rd.GetLanes() {
+		if lane.GetDirection() == "OUTPUT" {
+			continue
+		}
+		r.Waiting += lane.GetWaitingTrafficCount()
+		r.Lanes = append(r.Lanes, LaneData{
+			Index:        index,
+			Direction:    lane.GetDirection(),
+			State:        string(lane.GetState()),
+			Signal:       lane.GetSignal(),
+			Waiting:      lane.GetWaitingTrafficCount(),
+			Called:       lane.IsCalled(),
+			CrossingTime: in.GetCrossingTime(rd.GetName(), lane.GetDirection()),
+			Emergency:    lane.HasEmergencyVehicle(),
+			Bus:          lane.HasBus(),
+			WaitingSince: lane.GetLongestWaitingTraffic(),
+			Notified:     lane.GetNotified(),
+		})
+	}
+	return r
+}
+
+func (a *Api) getEventsV1(c *gin.Context) {
+	// Only the events of one type, like ?type=LightChanged
+	if c.Query("type") != "" && !validateEventTypes(c, []string{c.Query("type")}) {
+		return
+	}
+	a.getEvents(c)
+}
+
+// getEventTypes returns the event types a client can ask for, the ticks are only sent as tick in the stream
+func getEventTypes() []string {
+	var types []string
+	for _, name := range events.GetNames() {
+		if name != events.GetName(events.Ticked{}) {
+			types = append(types, name)
+		}
+	}
+	return types
+}
+
+// validateEventTypes returns false with a 400 when one of the types doesn't exist
+func validateEventTypes(c *gin.Context, types []string) bool {
+	for _, eventType := range types {
+		if !slices.Contains(getEventTypes(), eventType) {
+			abortWithReason(c, 400, fmt.Sprintf("unknown event type %s, expected one of %s", eventType, strings.Join(getEventTypes(), ", ")))
+			return false
+		}
+	}
+	return true
+}
+
+func getOpenApiV1(c *gin.Context) {
+	c.Data(200, "application/json; charset=utf-8", OPENAPI)
+}
@@ -0,0 +1,177 @@
+package intersectionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+func prepareApi(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	GLOBAL_INTERSECTION = intersection.NewIntersection([]*road.Road{
+		road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 2, CrossWalkEnabled: true}),
+		road.NewRoad(road.NewRoadInput{Name: "EAST", Forward: 1}),
+	})
+	a := NewApi(GLOBAL_INTERSECTION)
+	t.Cleanup(func() {
+		a.Close()
+		GLOBAL_INTERSECTION = nil
+	})
+	return a.newRouter()
+}
+
+func request(router *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func TestGetIntersectionV1(t *testing.T) {
+	router := prepareApi(t)
+	GLOBAL_INTERSECTION.FullStopLights()
+	GLOBAL_INTERSECTION.PressButton("NORTH", "CROSSWALK")
+	request(router, "POST", "/heartbeat", `{"calls": [{"road": "EAST", "lane": "FORWARD", "greenTick": 1, "orangeTick": 5, "redTick": 8}]}`)
+
+	reply := request(router, "GET", "/api/v1/intersection", "")
+	if reply.Code != 200 {
+		t.Fatalf(`The intersection should be found, got %d`, reply.Code)
+	}
+	var data IntersectionData
+	if err := json.Unmarshal(reply.Body.Bytes(), &data); err != nil {
+		t.Fatalf(`The reply should be JSON, got %v`, err)
+	}
+	if len(data.Roads) != 2 || data.Roads[0].Name != "NORTH" || len(data.Roads[0].Lanes) != 3 {
+		t.Fatalf(`NORTH should have 2 forward lanes and a crosswalk, got %+v`, data.Roads)
+	}
+	first, second := data.Roads[0].Lanes[0], data.Roads[0].Lanes[1]
+	if first.Direction != "FORWARD" || second.Direction != "FORWARD" || first.Index == second.Index || second.State != "RED" {
+		t.Fatalf(`The forward lanes should be told apart by their index, got %+v and %+v`, first, second)
+	}
+	crosswalk := data.Roads[0].Lanes[2]
+	if len(data.PendingCalls) != 1 || data.PendingCalls[0] != (LaneRefData{Road: "NORTH", Lane: "CROSSWALK", Index: crosswalk.Index}) {
+		t.Fatalf(`The crosswalk should have a pending call, got %v`, data.PendingCalls)
+	}
+	if data.Controller == nil || len(data.Controller.Calls) != 1 || data.Controller.Calls[0].Road != "EAST" {
+		t.Fatalf(`The phase of the traffic controller should come from the heartbeat, got %+v`, data.Controller)
+	}
+}
+
+func TestGetRoadV1(t *testing.T) {
+	router := prepareApi(t)
+	reply := request(router, "GET", "/api/v1/roads/EAST", "")
+	var data RoadData
+	json.Unmarshal(reply.Body.Bytes(), &data)
+	if reply.Code != 200 || data.Name != "EAST" || data.Metrics == nil {
+		t.Fatalf(`EAST should be found with its metrics, got %d %s`, reply.Code, reply.Body.String())
+	}
+}
+
+func TestErrorsV1(t *testing.T) {
+	router := prepareApi(t)
+	for path, status := range map[string]int{
+		"/api/v1/roads/SOUTH":        404,
+		"/api/v1/events?type=Other":  400,
+		"/api/v1/events?type=Ticked": 400,
+		"/api/v1/unknown":            404,
+	} {
+		reply := request(router, "GET", path, "")
+		var data ErrorData
+		if reply.Code != status || json.Unmarshal(reply.Body.Bytes(), &data) != nil || data.Reason == "" {
+			t.Fatalf(`%s should return %d with a reason, got %d %s`, path, status, reply.Code, reply.Body.String())
+		}
+	}
+}
+
+func TestOpenApiV1(t *testing.T) {
+	router := prepareApi(t)
+	reply := request(router, "GET", "/api/v1/openapi.json", "")
+	var data struct {
+		Paths      map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Enum []string `json:"enum"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(reply.Body.Bytes(), &data); err != nil {
+		t.Fatalf(`The description should be JSON, got %v`, err)
+	}
+
+	// Every route of the versioned API is described
+	for _, route := range router.Routes() {
+		path, ok := strings.CutPrefix(route.Path, "/api/v1")
+		if !ok {
+			continue
+		}
+		path = strings.ReplaceAll(path, ":road", "{road}")
+		if _, ok := data.Paths[path]; !ok {
+			t.Fatalf(`%s should be described`, route.Path)
+		}
+	}
+
+	// The objects match the code
+	for name, value := range map[string]any{"Controller": ControllerData{}, "Call": trafficcontroller.CallStatus{}, "LaneRef": LaneRefData{}, "Lane": LaneData{}} {
+		var fields map[string]any
+		encoded, _ := json.Marshal(value)
+		json.Unmarshal(encoded, &fields)
+		properties := data.Components.Schemas[name].Properties
+		if len(properties) != len(fields) {
+			t.Fatalf(`%s should have the fields %v, got %v`, name, fields, properties)
+		}
+		for field := range fields {
+			if _, ok := properties[field]; !ok {
+				t.Fatalf(`%s should describe %s`, name, field)
+			}
+		}
+	}
+
+	// The lists of values match the code
+	if states := data.Components.Schemas["Lane"].Properties["state"].Enum; len(states) != len(road.LIGHT_STATES) {
+		t.Fatalf(`All light states should be described, got %v`, states)
+	}
+	if types := data.Components.Schemas["Event"].Properties["type"].Enum; strings.Join(types, ",") != strings.Join(getEventTypes(), ",") {
+		t.Fatalf(`All event types should be described, got %v`, types)
+	}
+}
+
+func TestHeartbeatRefusesInvalidBody(t *testing.T) {
+	router := prepareApi(t)
+	if reply := request(router, "POST", "/heartbeat", `{"calls": 1}`); reply.Code != http.StatusBadRequest {
+		t.Fatalf(`An invalid heartbeat should be refused, got %d`, reply.Code)
+	}
+	if reply := request(router, "POST", "/heartbeat", ``); reply.Code != 200 {
+		t.Fatalf(`A heartbeat without a body should be accepted, got %d`, reply.Code)
+	}
+}
+
+func TestEventsPerApi(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	in := intersection.NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	other := intersection.NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	before := in.GetEvents().GetSubscriptionCount()
+	a := NewApi(in)
+
+	// Only the events of its own intersection
+	in.FullStopLights()
+	other.FullStopLights()
+	var data []RecentEvent
+	json.Unmarshal(request(a.newRouter(), "GET", "/api/v1/events", "").Body.Bytes(), &data)
+	if len(data) != 1 || data[0].Type != "LightChanged" {
+		t.Fatalf(`The API should have the light change of its intersection, got %+v`, data)
+	}
+
+	// A closed API no longer listens
+	a.Close()
+	if in.GetEvents().GetSubscriptionCount() != before {
+		t.Fatalf(`Close should stop listening to the events`)
+	}
+}
@@ -1,61 +1,91 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"math/rand"
+	"os"
+	"os/signal"
 	"time"
 
+	"github.com/martijnwiekens/go-learning/gointersection/config"
 	"github.com/martijnwiekens/go-learning/gointersection/intersection"
 	"github.com/martijnwiekens/go-learning/gointersection/intersectionapi"
+	"github.com/martijnwiekens/go-learning/gointersection/network"
 	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/simulation"
 	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
 	"github.com/martijnwiekens/go-learning/gointersection/ui"
 )
 
-// const TRAFFIC_CONTROLLER_MODE = "INTEGRATED"
-const TRAFFIC_CONTROLLER_MODE = "SEPERATED"
-const TICK_SPEED = 4 * time.Second
-
 func main() {
-	// Remember the roads
-	var roads []*road.Road
-
-	// Create North Road
-	var northRoad = road.NewRoad(road.NewRoadInput{Name: "NORTH", Left: 1, Right: 1, Forward: 2})
-	roads = append(roads, northRoad)
+	// Read the command line options
+	configPath := flag.String("config", "./intersection.json", "File with the layout of the intersection")
+	flag.Parse()
+
+	// Load the config
+	c, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tickSpeed := time.Duration(c.TickSpeed)
 
-	// Create South Road
-	var southRoad = road.NewRoad(road.NewRoadInput{Name: "SOUTH", All: 2})
-	roads = append(roads, southRoad)
+	// Create the random source, log the seed so the run can be repeated
+	random, seed := simulation.NewRandom(c.Seed)
+	log.Default().Println("Seed", seed)
 
-	// Create East Road
-	var eastRoad = road.NewRoad(road.NewRoadInput{Name: "EAST", Left: 1, All: 2})
-	roads = append(roads, eastRoad)
+	// Create the clock
+	var clock simulation.Clock = simulation.NewRealClock(tickSpeed)
+	if c.FastForward {
+		clock = simulation.NewVirtualClock(tickSpeed, time.Now())
+	}
 
-	// Create West Road
-	var westRoad = road.NewRoad(road.NewRoadInput{Name: "WEST", Left: 1, Right: 1, Forward: 2})
-	roads = append(roads, westRoad)
+	// Run the intersections of a corridor together
+	if c.Corridor != nil {
+		runCorridor(c, random, clock)
+		return
+	}
 
 	// Create Intersection
-	in := intersection.NewIntersection(roads)
+	in := intersection.NewIntersection(newRoads(c, c.Roads, random))
 
 	// Create the traffic controller
+	strategy, err := trafficcontroller.NewStrategy(c.Strategy, c.StrategySettings)
+	if err != nil {
+		log.Fatal(err)
+	}
 	var tc *trafficcontroller.TrafficController
-	if TRAFFIC_CONTROLLER_MODE == "INTEGRATED" {
+	if c.ControllerMode == "INTEGRATED" {
 		// Create traffic controller in the intersection
-		tc = trafficcontroller.NewTrafficController(TRAFFIC_CONTROLLER_MODE, in)
+		tc = trafficcontroller.NewTrafficController(in, strategy, c.SignalTiming)
+		tc.SetTransitPriority(c.TransitPriority)
 	} else {
+		// Let the lights FLASH when the seperate traffic controller is lost
+		in.EnableWatchdog(c.WatchdogTimeout)
+
 		// Start the API
-		go intersectionapi.StartApi(in)
+		go intersectionapi.StartApi(in, c.ApiAddress)
 
 		// Create seperate traffic controller
-		go trafficcontroller.StartTrafficControllerSeperated(TICK_SPEED)
+		go trafficcontroller.StartTrafficControllerSeperated(tickSpeed, c.GetConnectionSettings(), in.GetEvents(), strategy, c.SignalTiming, c.TransitPriority)
 	}
 
-	// Remember the current tick
-	currentTick := 0
+	// Show what happened in every tick below the intersection
+	var eventLog *ui.EventLog
+	if c.ControllerMode == "INTEGRATED" && !c.FastForward {
+		eventLog = ui.NewEventLog(in.GetEvents())
+	}
+
+	// Stop the run with CTRL+C
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	stopped := false
+
+	// Loop until we reach the max ticks, or forever
+	for !stopped && (c.MaxTicks == 0 || clock.GetCurrentTick() < c.MaxTicks) {
+		currentTick := clock.GetCurrentTick()
 
-	// Never ending loop
-	for {
 		// Log tick
 		log.Default().Println("------", "Tick", currentTick, "------")
 
@@ -63,21 +93,135 @@ func main() {
 		in.Tick(currentTick)
 
 		// Tick the traffic controller
-		if TRAFFIC_CONTROLLER_MODE == "INTEGRATED" {
-			tc.Tick(currentTick, 0)
+		if c.ControllerMode == "INTEGRATED" {
+			tc.Tick(currentTick)
 		}
 
-		// Print the intersection
-		if TRAFFIC_CONTROLLER_MODE == "INTEGRATED" {
+		// Print the intersection, fast forward only prints the end result
+		if eventLog != nil {
 			ui.PrintTick(currentTick)
 			ui.PrintTotalCarsWaiting(in)
 			ui.PrintIntersection(in)
+			eventLog.Print()
+		}
+
+		// Check if we should stop
+		select {
+		case <-interrupt:
+			stopped = true
+			continue
+		default:
+		}
+
+		// Wait for the next tick
+		clock.Next()
+	}
+
+	// The API can still change the intersection, print a consistent end result
+	in.View(func() {
+		// Print the end result of fast forward
+		if c.FastForward {
+			ui.PrintTick(in.CurrentTick)
+			ui.PrintTotalCarsWaiting(in)
+			ui.PrintIntersection(in)
+		}
+
+		// Show how well the traffic controller did
+		ui.PrintSummary(in)
+	})
+}
+
+func newRoads(c *config.Config, roadConfigs []config.Road, random *rand.Rand) []*road.Road {
+	var roads []*road.Road
+	for _, roadConfig := range roadConfigs {
+		input := roadConfig.ToRoadInput(random)
+		input.SignalTiming = c.SignalTiming
+		roads = append(roads, road.NewRoad(input))
+	}
+	return roads
+}
+
+// runCorridor runs the intersections of the corridor, every intersection has its own traffic controller
+func runCorridor(c *config.Config, random *rand.Rand, clock simulation.Clock) {
+	// The offsets of the green wave follow the travel time between the intersections
+	coordination := c.Corridor.Coordination.WithDefaults()
+	travelTimes := c.Corridor.GetTravelTimes()
+	offsets := network.GreenWaveOffsets(travelTimes, coordination.CycleLength)
+
+	// Create the intersections
+	n := network.NewNetwork()
+	for index, intersectionConfig := range c.Corridor.Intersections {
+		in := intersection.NewIntersection(newRoads(c, c.GetCorridorRoads(index), random))
+
+		// Create the traffic controller
+		var strategy trafficcontroller.Strategy
+		if coordination.Enabled {
+			log.Default().Println("Intersection", intersectionConfig.Name, "has an offset of", offsets[index], "ticks")
+			strategy = trafficcontroller.NewCoordinatedStrategy(coordination.CycleLength, offsets[index], coordination.CorridorGreen)
+		} else {
+			var err error
+			strategy, err = trafficcontroller.NewStrategy(c.Strategy, c.StrategySettings)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		tc := trafficcontroller.NewTrafficController(in, strategy, c.SignalTiming)
+		tc.SetTransitPriority(c.TransitPriority)
+		n.AddNode(intersectionConfig.Name, in, tc)
+	}
+
+	// Show what happened in every tick below the intersections
+	eventLogs := map[string]*ui.EventLog{}
+	if !c.FastForward {
+		for _, node := range n.GetNodes() {
+			eventLogs[node.Name] = ui.NewEventLog(node.Intersection.GetEvents())
+		}
+	}
+	if err := n.AddCorridor(travelTimes); err != nil {
+		log.Fatal(err)
+	}
+
+	// Stop the run with CTRL+C
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	stopped := false
+
+	// Loop until we reach the max ticks, or forever
+	for !stopped && (c.MaxTicks == 0 || clock.GetCurrentTick() < c.MaxTicks) {
+		currentTick := clock.GetCurrentTick()
+
+		// Log tick
+		log.Default().Println("------", "Tick", currentTick, "------")
+
+		// Tick the intersections and drive the traffic between them
+		n.Tick(currentTick)
+
+		// Print the intersections, fast forward only prints the end result
+		if !c.FastForward {
+			ui.PrintTick(currentTick)
+			for _, node := range n.GetNodes() {
+				fmt.Println("Intersection", node.Name)
+				ui.PrintTotalCarsWaiting(node.Intersection)
+				ui.PrintIntersection(node.Intersection)
+				eventLogs[node.Name].Print()
+			}
 		}
 
-		// Increment the tick
-		currentTick++
+		// Check if we should stop
+		select {
+		case <-interrupt:
+			stopped = true
+			continue
+		default:
+		}
+
+		// Wait for the next tick
+		clock.Next()
+	}
 
-		// Sleep for 1 second
-		time.Sleep(TICK_SPEED)
+	// Show how well the traffic controllers did
+	for _, node := range n.GetNodes() {
+		ui.PrintSummary(node.Intersection)
 	}
+	ui.PrintTripSummary(n.GetTrips())
 }
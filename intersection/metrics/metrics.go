@@ -0,0 +1,238 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Throughput is counted as vehicles per this amount of ticks
+const THROUGHPUT_WINDOW int = 100
+
+// Recorder keeps the metrics of a single lane
+type Recorder struct {
+	delays           map[int]int // Vehicles by the ticks they waited before they crossed, it doesn't grow with the length of the run
+	delayBuckets     []int       // Vehicles in every bucket of DELAY_BUCKETS, not cumulative
+	vehicles         int         // Vehicles that crossed
+	totalDelay       int         // Ticks all vehicles waited together
+	buses            int         // Buses that crossed, buses are also counted as vehicles
+	totalBusDelay    int         // Ticks all buses waited together
+	arrived          int         // Vehicles that arrived in the lane
+	ticks            int         // Amount of ticks recorded
+	maxQueue         int         // Longest queue seen
+	greenPhases      int         // Times the light turned GREEN
+	greenTicks       int         // Ticks the light was GREEN
+	wastedGreenTicks int         // Ticks the light was GREEN without any traffic waiting
+	stops            int         // Vehicles that crossed after they stopped for the light
+}
+
+type Summary struct {
+	Arrived          int     `json:"arrived"`          // Vehicles that arrived at the intersection
+	Vehicles         int     `json:"vehicles"`         // Vehicles that crossed the intersection
+	AverageDelay     float64 `json:"averageDelay"`     // Average ticks a vehicle waited
+	P95Delay         int     `json:"p95Delay"`         // 95% of the vehicles waited this amount of ticks or less
+	Throughput       float64 `json:"throughput"`       // Vehicles that crossed per THROUGHPUT_WINDOW ticks
+	MaxQueue         int     `json:"maxQueue"`         // Longest queue of a single lane
+	GreenPhases      int     `json:"greenPhases"`      // Times a light turned GREEN
+	GreenTicks       int     `json:"greenTicks"`       // Ticks a light was GREEN
+	WastedGreenTicks int     `json:"wastedGreenTicks"` // Ticks a light was GREEN without any traffic waiting
+	Buses            int     `json:"buses"`            // Buses that crossed the intersection
+	BusAverageDelay  float64 `json:"busAverageDelay"`  // Average ticks a bus waited
+	Stops            int     `json:"stops"`            // Vehicles that stopped for the light before they crossed
+	StopsPerVehicle  float64 `json:"stopsPerVehicle"`  // Part of the vehicles that stopped, between 0 and 1
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{delays: map[int]int{}, delayBuckets: make([]int, len(DELAY_BUCKETS))}
+}
+
+func (r *Recorder) RecordArrival() {
+	r.arrived++
+}
+
+func (r *Recorder) RecordCrossing(delay int) {
+	r.vehicles++
+	r.totalDelay += delay
+	r.delays[delay]++
+
+	// Count the vehicle in the first bucket it fits, a longer delay is only in the +Inf bucket
+	for index, bucket := range DELAY_BUCKETS {
+		if delay <= bucket {
+			r.delayBuckets[index]++
+			break
+		}
+	}
+}
+
+// RecordStop is called when a vehicle crossed after it stopped for the light
+func (r *Recorder) RecordStop() {
+	r.stops++
+}
+
+func (r *Recorder) RecordBusCrossing(delay int) {
+	r.buses++
+	r.totalBusDelay += delay
+}
+
+// RecordTick is called once every tick with the queue of the lane
+func (r *Recorder) RecordTick(queue int, green bool) {
+	r.ticks++
+	r.maxQueue = max(r.maxQueue, queue)
+	if green {
+		r.greenTicks++
+		if queue == 0 {
+			r.wastedGreenTicks++
+		}
+	}
+}
+
+func (r *Recorder) RecordGreenPhase() {
+	r.greenPhases++
+}
+
+// GetArrived returns the vehicles that arrived in the lane since the start
+func (r *Recorder) GetArrived() int {
+	return r.arrived
+}
+
+func (r *Recorder) GetSummary() Summary {
+	return Summarize(r)
+}
+
+// Summarize combines the metrics of lanes, like all the lanes of a road
+func Summarize(recorders ...*Recorder) Summary {
+	var summary Summary
+	delays := map[int]int{}
+	totalDelay := 0
+	totalBusDelay := 0
+	ticks := 0
+	for _, r := range recorders {
+		for delay, vehicles := range r.delays {
+			delays[delay] += vehicles
+		}
+		summary.Vehicles += r.vehicles
+		summary.Buses += r.buses
+		totalDelay += r.totalDelay
+		totalBusDelay += r.totalBusDelay
+		ticks = max(ticks, r.ticks)
+		summary.Arrived += r.arrived
+		summary.MaxQueue = max(summary.MaxQueue, r.maxQueue)
+		summary.GreenPhases += r.greenPhases
+		summary.GreenTicks += r.greenTicks
+		summary.WastedGreenTicks += r.wastedGreenTicks
+		summary.Stops += r.stops
+	}
+
+	// Calculate the delay
+	if summary.Vehicles > 0 {
+		summary.AverageDelay = float64(totalDelay) / float64(summary.Vehicles)
+		summary.P95Delay = percentile(delays, summary.Vehicles, 95)
+		summary.StopsPerVehicle = float64(summary.Stops) / float64(summary.Vehicles)
+	}
+	if summary.Buses > 0 {
+		summary.BusAverageDelay = float64(totalBusDelay) / float64(summary.Buses)
+	}
+
+	// Calculate the throughput
+	if ticks > 0 {
+		summary.Throughput = float64(summary.Vehicles) * float64(THROUGHPUT_WINDOW) / float64(ticks)
+	}
+	return summary
+}
+
+// percentile uses the nearest rank, so the result is always a delay that really happened.
+// The counts have the amount of vehicles for every delay, total is the sum of the counts.
+func percentile(counts map[int]int, total int, percent int) int {
+	var values []int
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	rank := max(int(math.Ceil(float64(percent)/100*float64(total))), 1)
+	seen := 0
+	for _, value := range values {
+		seen += counts[value]
+		if seen >= rank {
+			return value
+		}
+	}
+	return 0
+}
+
+// ODMatrix counts the vehicles that crossed from every origin road to every destination road
+type ODMatrix struct {
+	counts map[string]map[string]int
+}
+
+func NewODMatrix() *ODMatrix {
+	return &ODMatrix{counts: map[string]map[string]int{}}
+}
+
+func (m *ODMatrix) Record(origin string, destination string) {
+	if m.counts[origin] == nil {
+		m.counts[origin] = map[string]int{}
+	}
+	m.counts[origin][destination]++
+}
+
+func (m *ODMatrix) Get(origin string, destination string) int {
+	return m.counts[origin][destination]
+}
+
+// GetCounts returns a copy of the vehicles by origin and destination
+func (m *ODMatrix) GetCounts() map[string]map[string]int {
+	counts := map[string]map[string]int{}
+	for origin, destinations := range m.counts {
+		counts[origin] = map[string]int{}
+		for destination, count := range destinations {
+			counts[origin][destination] = count
+		}
+	}
+	return counts
+}
+
+// TripRecorder keeps the stops of the vehicles that left a network of intersections
+type TripRecorder struct {
+	trips map[int]*TripSummary // Trips by the amount of intersections crossed
+}
+
+type TripSummary struct {
+	Intersections int     `json:"intersections"` // Intersections the vehicles crossed
+	Trips         int     `json:"trips"`         // Vehicles that left the network
+	Stops         int     `json:"stops"`         // Times the vehicles stopped for a light
+	StopsPerTrip  float64 `json:"stopsPerTrip"`  // Average stops of a vehicle
+}
+
+func NewTripRecorder() *TripRecorder {
+	return &TripRecorder{trips: map[int]*TripSummary{}}
+}
+
+func (r *TripRecorder) Record(intersections int, stops int) {
+	trip, ok := r.trips[intersections]
+	if !ok {
+		trip = &TripSummary{Intersections: intersections}
+		r.trips[intersections] = trip
+	}
+	trip.Trips++
+	trip.Stops += stops
+	trip.StopsPerTrip = float64(trip.Stops) / float64(trip.Trips)
+}
+
+// GetSummaries returns the trips by the amount of intersections crossed, the shortest trips first
+func (r *TripRecorder) GetSummaries() []TripSummary {
+	var summaries []TripSummary
+	for _, trip := range r.trips {
+		summaries = append(summaries, *trip)
+	}
+	sort.Slice(summaries, func(a, b int) bool {
+		return summaries[a].Intersections < summaries[b].Intersections
+	})
+	return summaries
+}
+
+// Get returns the trips that crossed the amount of intersections
+func (r *TripRecorder) Get(intersections int) TripSummary {
+	if trip, ok := r.trips[intersections]; ok {
+		return *trip
+	}
+	return TripSummary{Intersections: intersections}
+}
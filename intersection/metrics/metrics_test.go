@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	first := NewRecorder()
+	second := NewRecorder()
+
+	// 20 vehicles with a delay of 1 to 20 ticks
+	for delay := 1; delay <= 20; delay++ {
+		if delay%2 == 0 {
+			first.RecordCrossing(delay)
+		} else {
+			second.RecordCrossing(delay)
+		}
+	}
+
+	// 200 ticks, the first lane is green half of the time
+	for tick := 0; tick < 200; tick++ {
+		first.RecordTick(tick%7, tick < 100)
+		second.RecordTick(3, false)
+	}
+	first.RecordGreenPhase()
+
+	summary := Summarize(first, second)
+	if summary.Vehicles != 20 {
+		t.Fatalf(`Vehicles should be 20, got %d`, summary.Vehicles)
+	}
+	if summary.AverageDelay != 10.5 {
+		t.Fatalf(`AverageDelay should be 10.5, got %f`, summary.AverageDelay)
+	}
+	if summary.P95Delay != 19 {
+		t.Fatalf(`P95Delay should be 19, got %d`, summary.P95Delay)
+	}
+	if summary.Throughput != 10 {
+		t.Fatalf(`Throughput should be 10 per %d ticks, got %f`, THROUGHPUT_WINDOW, summary.Throughput)
+	}
+	if summary.MaxQueue != 6 {
+		t.Fatalf(`MaxQueue should be 6, got %d`, summary.MaxQueue)
+	}
+	if summary.GreenPhases != 1 || summary.GreenTicks != 100 || summary.WastedGreenTicks != 15 {
+		t.Fatalf(`Green time is wrong: %+v`, summary)
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	summary := Summarize(NewRecorder())
+	if summary.Vehicles != 0 || summary.AverageDelay != 0 || summary.P95Delay != 0 || summary.Throughput != 0 {
+		t.Fatalf(`Empty summary should be zero, got %+v`, summary)
+	}
+}
+
+func TestRecorderDoesNotGrow(t *testing.T) {
+	r := NewRecorder()
+	for vehicle := 0; vehicle < 100000; vehicle++ {
+		r.RecordCrossing(vehicle % 20)
+	}
+
+	// Only the different delays are kept
+	if len(r.delays) != 20 {
+		t.Fatalf(`The recorder should keep 20 delays, got %d`, len(r.delays))
+	}
+	summary := r.GetSummary()
+	if summary.Vehicles != 100000 || summary.AverageDelay != 9.5 || summary.P95Delay != 18 {
+		t.Fatalf(`The summary should count every vehicle, got %+v`, summary)
+	}
+}
+
+func TestPrometheusHistogram(t *testing.T) {
+	r := NewRecorder()
+	for _, delay := range []int{0, 1, 3, 12, 500} {
+		r.RecordCrossing(delay)
+	}
+
+	w := &PrometheusWriter{}
+	w.Family("wait_ticks", "histogram", "Ticks a vehicle waited.")
+	w.Histogram("wait_ticks", Labels{{"road", "NORTH"}}, r.GetDelayHistogram())
+	output := w.String()
+
+	for _, expected := range []string{
+		"# TYPE wait_ticks histogram\n",
+		`wait_ticks_bucket{road="NORTH",le="1"} 2` + "\n",
+		`wait_ticks_bucket{road="NORTH",le="5"} 3` + "\n",
+		`wait_ticks_bucket{road="NORTH",le="20"} 4` + "\n",
+		`wait_ticks_bucket{road="NORTH",le="200"} 4` + "\n",
+		`wait_ticks_bucket{road="NORTH",le="+Inf"} 5` + "\n",
+		`wait_ticks_sum{road="NORTH"} 516` + "\n",
+		`wait_ticks_count{road="NORTH"} 5` + "\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf(`Output should contain %q, got:\n%s`, expected, output)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Upper bounds in ticks of the wait time histogram
+var DELAY_BUCKETS = []int{1, 2, 5, 10, 20, 50, 100, 200}
+
+// ControllerCounters count the decisions of one traffic controller, it sends them with its heartbeat
+type ControllerCounters struct {
+	PatternSteps         int64 `json:"patternSteps"`
+	PendingCallOverrides int64 `json:"pendingCallOverrides"`
+	CollisionWarnings    int64 `json:"collisionWarnings"`
+	Preemptions          int64 `json:"preemptions"`
+	PreemptionDelay      int64 `json:"preemptionDelay"`   // Ticks the traffic on the other lanes waited during the preemptions
+	TransitExtensions    int64 `json:"transitExtensions"` // Ticks a GREEN light was extended for a late bus
+	TransitReductions    int64 `json:"transitReductions"` // Ticks a GREEN light was shortened for a late bus
+}
+
+func (c *ControllerCounters) AddPatternStep() {
+	c.PatternSteps++
+}
+
+func (c *ControllerCounters) AddPendingCallOverride() {
+	c.PendingCallOverrides++
+}
+
+func (c *ControllerCounters) AddCollisionWarning() {
+	c.CollisionWarnings++
+}
+
+// AddPreemption counts a preemption for an emergency vehicle and the ticks it cost the other lanes
+func (c *ControllerCounters) AddPreemption(delay int) {
+	c.Preemptions++
+	c.PreemptionDelay += int64(delay)
+}
+
+func (c *ControllerCounters) AddTransitExtension(ticks int) {
+	c.TransitExtensions += int64(ticks)
+}
+
+func (c *ControllerCounters) AddTransitReduction(ticks int) {
+	c.TransitReductions += int64(ticks)
+}
+
+type Histogram struct {
+	Buckets []int // Cumulative count for every bucket in DELAY_BUCKETS
+	Count   int
+	Sum     int
+}
+
+func (r *Recorder) GetDelayHistogram() Histogram {
+	// The buckets are counted while the vehicles cross, a scrape only adds them up
+	histogram := Histogram{Buckets: make([]int, len(DELAY_BUCKETS)), Count: r.vehicles, Sum: r.totalDelay}
+	cumulative := 0
+	for index, vehicles := range r.delayBuckets {
+		cumulative += vehicles
+		histogram.Buckets[index] = cumulative
+	}
+	return histogram
+}
+
+// Labels of a sample, written in the order they are given
+type Labels [][2]string
+
+// PrometheusWriter writes metrics in the Prometheus text format.
+// All samples of a metric should be written right after its Family.
+type PrometheusWriter struct {
+	output strings.Builder
+}
+
+func (w *PrometheusWriter) Family(name string, kind string, help string) {
+	fmt.Fprintf(&w.output, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(&w.output, "# TYPE %s %s\n", name, kind)
+}
+
+func (w *PrometheusWriter) Sample(name string, labels Labels, value float64) {
+	w.output.WriteString(name)
+	if len(labels) > 0 {
+		var parts []string
+		for _, label := range labels {
+			parts = append(parts, label[0]+"="+strconv.Quote(label[1]))
+		}
+		w.output.WriteString("{" + strings.Join(parts, ",") + "}")
+	}
+	w.output.WriteString(" " + strconv.FormatFloat(value, 'f', -1, 64) + "\n")
+}
+
+func (w *PrometheusWriter) Histogram(name string, labels Labels, histogram Histogram) {
+	for index, bucket := range DELAY_BUCKETS {
+		w.Sample(name+"_bucket", labels.With("le", strconv.Itoa(bucket)), float64(histogram.Buckets[index]))
+	}
+	w.Sample(name+"_bucket", labels.With("le", "+Inf"), float64(histogram.Count))
+	w.Sample(name+"_sum", labels, float64(histogram.Sum))
+	w.Sample(name+"_count", labels, float64(histogram.Count))
+}
+
+func (w *PrometheusWriter) String() string {
+	return w.output.String()
+}
+
+// With returns a copy of the labels with one extra label
+func (l Labels) With(name string, value string) Labels {
+	return append(append(Labels{}, l...), [2]string{name, value})
+}
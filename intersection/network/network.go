@@ -0,0 +1,175 @@
+package network
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/traffic"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+// Network are intersections where the traffic that leaves one intersection drives to the next one
+type Network struct {
+	nodes     []*Node
+	links     []*Link
+	inTransit []*vehicleInTransit
+	trips     *metrics.TripRecorder // Stops of the vehicles that left the network
+}
+
+// Node is an intersection of the network with its own traffic controller
+type Node struct {
+	Name         string
+	Intersection *intersection.Intersection
+	Controller   *trafficcontroller.TrafficController
+}
+
+// Link connects the OUTPUT lane of a road of one intersection to a road of the next intersection
+type Link struct {
+	From       string // Name of the intersection the traffic leaves
+	FromRoad   string // Road of the OUTPUT lane the traffic leaves on
+	To         string // Name of the intersection the traffic drives to
+	ToRoad     string // Road the traffic arrives on
+	TravelTime int    // Ticks the traffic drives between the intersections
+}
+
+// vehicleInTransit is traffic that drives between two intersections
+type vehicleInTransit struct {
+	traffic     road.Traffic
+	link        *Link
+	arrivalTick int
+}
+
+func NewNetwork() *Network {
+	return &Network{trips: metrics.NewTripRecorder()}
+}
+
+// AddNode adds an intersection to the network, the name is used in the events of the intersection
+func (n *Network) AddNode(name string, in *intersection.Intersection, tc *trafficcontroller.TrafficController) *Node {
+	in.SetName(name)
+	node := &Node{Name: name, Intersection: in, Controller: tc}
+	n.nodes = append(n.nodes, node)
+	return node
+}
+
+// AddLink lets the traffic on the OUTPUT lane of a road drive to the road of another intersection
+func (n *Network) AddLink(link Link) error {
+	from := n.GetNode(link.From)
+	if from == nil {
+		return fmt.Errorf("intersection %s doesn't exist", link.From)
+	}
+	to := n.GetNode(link.To)
+	if to == nil {
+		return fmt.Errorf("intersection %s doesn't exist", link.To)
+	}
+	if from.Intersection.GetRoadByName(link.FromRoad) == nil {
+		return fmt.Errorf("road %s of intersection %s doesn't exist", link.FromRoad, link.From)
+	}
+	if to.Intersection.GetRoadByName(link.ToRoad) == nil {
+		return fmt.Errorf("road %s of intersection %s doesn't exist", link.ToRoad, link.To)
+	}
+	if link.TravelTime < 0 {
+		return fmt.Errorf("travel time from %s to %s can't be negative", link.From, link.To)
+	}
+	n.links = append(n.links, &link)
+	return nil
+}
+
+// AddCorridor links the intersections in the order they were added, traffic drives from WEST to EAST and back.
+// There is a travel time between every two intersections.
+func (n *Network) AddCorridor(travelTimes []int) error {
+	if len(travelTimes) != len(n.nodes)-1 {
+		return fmt.Errorf("a corridor of %d intersections needs %d travel times, got %d", len(n.nodes), len(n.nodes)-1, len(travelTimes))
+	}
+	for index, travelTime := range travelTimes {
+		from := n.nodes[index].Name
+		to := n.nodes[index+1].Name
+		if err := n.AddLink(Link{From: from, FromRoad: "EAST", To: to, ToRoad: "WEST", TravelTime: travelTime}); err != nil {
+			return err
+		}
+		if err := n.AddLink(Link{From: to, FromRoad: "WEST", To: from, ToRoad: "EAST", TravelTime: travelTime}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GreenWaveOffsets returns the offset of every intersection of a corridor, the corridor turns GREEN
+// at the next intersection when the first vehicle of the GREEN arrives there
+func GreenWaveOffsets(travelTimes []int, cycleLength int) []int {
+	offsets := []int{0}
+	for _, travelTime := range travelTimes {
+		// The vehicle needs a tick on the OUTPUT lane before it drives to the next intersection
+		offsets = append(offsets, (offsets[len(offsets)-1]+travelTime+1)%cycleLength)
+	}
+	return offsets
+}
+
+func (n *Network) Tick(currentTick int) {
+	// Drive the traffic that arrived to its intersection
+	var inTransit []*vehicleInTransit
+	for _, vehicle := range n.inTransit {
+		if vehicle.arrivalTick > currentTick {
+			inTransit = append(inTransit, vehicle)
+			continue
+		}
+		to := n.GetNode(vehicle.link.To)
+		to.Intersection.GetRoadByName(vehicle.link.ToRoad).AddIncomingTraffic(vehicle.traffic, currentTick)
+	}
+	n.inTransit = inTransit
+
+	// Tick every intersection and its traffic controller
+	for _, node := range n.nodes {
+		node.Intersection.Tick(currentTick)
+		node.Controller.Tick(currentTick)
+	}
+
+	// Drive the traffic that left an intersection to the next one, or out of the network
+	for _, node := range n.nodes {
+		for roadName, exited := range node.Intersection.GetExitedTraffic() {
+			link := n.getLink(node.Name, roadName)
+			for _, t := range exited {
+				if link != nil {
+					n.inTransit = append(n.inTransit, &vehicleInTransit{traffic: t, link: link, arrivalTick: currentTick + link.TravelTime})
+					continue
+				}
+				if routed, ok := t.(road.Routed); ok {
+					n.recordTrip(routed.GetRoute())
+				}
+			}
+		}
+	}
+}
+
+func (n *Network) recordTrip(route *traffic.Route) {
+	log.Default().Println("N: Vehicle left the network after", route.Intersections, "intersections and", route.Stops, "stops")
+	n.trips.Record(route.Intersections, route.Stops)
+}
+
+func (n *Network) getLink(from string, fromRoad string) *Link {
+	for _, link := range n.links {
+		if link.From == from && link.FromRoad == fromRoad {
+			return link
+		}
+	}
+	return nil
+}
+
+func (n *Network) GetNodes() []*Node {
+	return n.nodes
+}
+
+func (n *Network) GetNode(name string) *Node {
+	for _, node := range n.nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func (n *Network) GetTrips() *metrics.TripRecorder {
+	return n.trips
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+	"github.com/martijnwiekens/go-learning/gointersection/traffic"
+	"github.com/martijnwiekens/go-learning/gointersection/trafficcontroller"
+)
+
+// newCorridorRoads creates the roads of an intersection of the corridor, the corridor is busier than the side roads
+func newCorridorRoads(random *rand.Rand) []*road.Road {
+	return []*road.Road{
+		road.NewRoad(road.NewRoadInput{Name: "NORTH", All: 1, NewCarSpeed: 30, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "SOUTH", All: 1, NewCarSpeed: 30, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "EAST", Left: 1, Forward: 2, Right: 1, NewCarSpeed: 12, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "WEST", Left: 1, Forward: 2, Right: 1, NewCarSpeed: 12, Random: random}),
+	}
+}
+
+// runCorridor drives a corridor of 3 intersections, with or without a green wave
+func runCorridor(seed int64, ticks int, coordinated bool) *metrics.TripRecorder {
+	// Run without the logs
+	output := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(output)
+
+	random := rand.New(rand.NewSource(seed))
+	travelTimes := []int{10, 15}
+	offsets := GreenWaveOffsets(travelTimes, trafficcontroller.DEFAULT_CYCLE_LENGTH)
+	n := NewNetwork()
+	for index, name := range []string{"A", "B", "C"} {
+		in := intersection.NewIntersection(newCorridorRoads(random))
+		var strategy trafficcontroller.Strategy = trafficcontroller.NewPatternStrategy()
+		if coordinated {
+			strategy = trafficcontroller.NewCoordinatedStrategy(trafficcontroller.DEFAULT_CYCLE_LENGTH, offsets[index], trafficcontroller.DEFAULT_CYCLE_LENGTH/2)
+		}
+		n.AddNode(name, in, trafficcontroller.NewTrafficController(in, strategy, road.SignalTiming{}))
+	}
+	n.AddCorridor(travelTimes)
+	for tick := 0; tick < ticks; tick++ {
+		n.Tick(tick)
+	}
+	return n.GetTrips()
+}
+
+func TestGreenWaveOffsets(t *testing.T) {
+	offsets := GreenWaveOffsets([]int{10, 15, 40}, 60)
+	expected := []int{0, 11, 27, 8}
+	for index := range expected {
+		if offsets[index] != expected[index] {
+			t.Fatalf(`Offsets should be %v, got %v`, expected, offsets)
+		}
+	}
+}
+
+func TestTrafficDrivesToNextIntersection(t *testing.T) {
+	n := NewNetwork()
+	for _, name := range []string{"A", "B"} {
+		// New cars only arrive on tick 0, so only the test car drives
+		roads := []*road.Road{
+			road.NewRoad(road.NewRoadInput{Name: "EAST", Forward: 1, NewCarSpeed: 255}),
+			road.NewRoad(road.NewRoadInput{Name: "WEST", Forward: 1, NewCarSpeed: 255}),
+		}
+		in := intersection.NewIntersection(roads)
+		n.AddNode(name, in, trafficcontroller.NewTrafficController(in, trafficcontroller.NewPatternStrategy(), road.SignalTiming{}))
+	}
+	if err := n.AddCorridor([]int{5}); err != nil {
+		t.Fatalf(`AddCorridor returned an error: %v`, err)
+	}
+
+	// The car leaves A on tick 1 and arrives at B after the travel time
+	car := &traffic.Car{FirstTick: 1, Route: traffic.Route{Stops: 1, Intersections: 1}}
+	n.GetNode("A").Intersection.GetRoadByName("EAST").AddOutputTraffic(car)
+	west := n.GetNode("B").Intersection.GetRoadByName("WEST")
+	for tick := 1; tick <= 5; tick++ {
+		n.Tick(tick)
+		if west.GetMetrics().Arrived != 0 {
+			t.Fatalf(`The car should still drive to B on tick %d`, tick)
+		}
+	}
+	n.Tick(6)
+	if west.GetMetrics().Arrived != 1 {
+		t.Fatalf(`The car should arrive at B on tick 6, got %d arrivals`, west.GetMetrics().Arrived)
+	}
+
+	// The traffic controller took control on tick 0, so the lights still flash and the car crosses B right away
+	if car.Origin != "WEST" || car.FirstTick != 6 || car.Stops != 1 || car.Intersections != 2 {
+		t.Fatalf(`The car should get a new route and keep its trip, got %+v`, car)
+	}
+}
+
+func TestGreenWaveReducesStops(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the corridor twice")
+	}
+
+	// The same seed gives the same traffic, only the traffic controllers are different
+	without := runCorridor(1, 5000, false).Get(3)
+	with := runCorridor(1, 5000, true).Get(3)
+	if without.Trips == 0 || with.Trips == 0 {
+		t.Fatalf(`Vehicles should drive the whole corridor, got %d and %d`, without.Trips, with.Trips)
+	}
+	if with.StopsPerTrip >= without.StopsPerTrip {
+		t.Fatalf(`The green wave should lower the stops, got %.2f with and %.2f without`, with.StopsPerTrip, without.StopsPerTrip)
+	}
+}
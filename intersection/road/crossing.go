@@ -0,0 +1,80 @@
+package road
+
+import (
+	"math"
+)
+
+// A tick is a second in the simulation, so speeds are in meters per tick
+const LANE_WIDTH float64 = 3.5         // Meters of a lane for cars
+const BICYCLE_LANE_WIDTH float64 = 2.0 // Meters of a bicycle lane
+const WALKING_SPEED float64 = 1.2      // Meters per tick of a human
+const BICYCLE_SPEED float64 = 4.0      // Meters per tick of a bicycle
+const BICYCLES_PER_TICK uint8 = 3      // Bicycles that leave a GREEN bicycle lane together
+
+// Pedestrian signals of a CROSSWALK lane
+const (
+	SIGNAL_WALK               = "WALK"
+	SIGNAL_FLASHING_DONT_WALK = "FLASHING-DONT-WALK" // Humans on the crosswalk finish crossing, nobody starts
+	SIGNAL_DONT_WALK          = "DONT-WALK"
+	SIGNAL_DARK               = "DARK"
+)
+
+// GetPedestrianSignal returns what a crosswalk shows for the state of its light
+func GetPedestrianSignal(state LightState) string {
+	switch state {
+	case STATE_GREEN:
+		return SIGNAL_WALK
+	case STATE_ORANGE:
+		return SIGNAL_FLASHING_DONT_WALK
+	case STATE_RED, STATE_RED_ORANGE:
+		return SIGNAL_DONT_WALK
+	}
+	return SIGNAL_DARK
+}
+
+func IsCrossingLane(laneName string) bool {
+	return laneName == "CROSSWALK" || laneName == "BICYCLE"
+}
+
+// GetWidth returns the meters a human walks to cross the road
+func (r *Road) GetWidth() float64 {
+	var width float64
+	for _, lane := range r.lanes {
+		switch lane.direction {
+		case "CROSSWALK":
+			continue
+		case "BICYCLE":
+			width += BICYCLE_LANE_WIDTH
+		default:
+			width += LANE_WIDTH
+		}
+	}
+	return width
+}
+
+// GetCrossingTime returns the ticks it takes to cross a distance, humans walk and bicycles ride
+func GetCrossingTime(laneName string, meters float64) int {
+	speed := WALKING_SPEED
+	if laneName == "BICYCLE" {
+		speed = BICYCLE_SPEED
+	}
+	return int(math.Ceil(meters / speed))
+}
+
+// PressButton registers a call for the crosswalk or bicycle lane, it is cleared when the lane turns GREEN
+func (l *Lane) PressButton() {
+	l.called = true
+}
+
+// IsCalled returns whether or not somebody is waiting or pressed the button
+func (l *Lane) IsCalled() bool {
+	return l.called || len(l.waitingTraffic) > 0
+}
+
+// GetSignal returns the pedestrian signal of a crosswalk, the light state for other lanes
+func (l *Lane) GetSignal() string {
+	if l.direction == "CROSSWALK" {
+		return GetPedestrianSignal(l.state)
+	}
+	return string(l.state)
+}
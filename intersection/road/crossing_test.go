@@ -0,0 +1,40 @@
+package road
+
+import "testing"
+
+func TestCrossingTime(t *testing.T) {
+	r := NewRoad(NewRoadInput{Name: "NORTH", Forward: 2, Right: 1, CrossWalkEnabled: true, BycyclesEnabled: true})
+
+	// The OUTPUT lane, 3 lanes and a bicycle lane, the crosswalk itself doesn't count
+	if r.GetWidth() != 4*LANE_WIDTH+BICYCLE_LANE_WIDTH {
+		t.Fatalf(`Road should be %.1f meters wide, got %.1f`, 4*LANE_WIDTH+BICYCLE_LANE_WIDTH, r.GetWidth())
+	}
+	if GetCrossingTime("CROSSWALK", 16) != 14 {
+		t.Fatalf(`Humans should cross 16 meters in 14 ticks, got %d`, GetCrossingTime("CROSSWALK", 16))
+	}
+	if GetCrossingTime("BICYCLE", 16) != 4 {
+		t.Fatalf(`Bicycles should cross 16 meters in 4 ticks, got %d`, GetCrossingTime("BICYCLE", 16))
+	}
+}
+
+func TestButtonCallsLane(t *testing.T) {
+	r := NewRoad(NewRoadInput{Name: "NORTH", Forward: 1, CrossWalkEnabled: true})
+	lane := r.GetLanesByName("CROSSWALK")[0]
+	if lane.IsCalled() {
+		t.Fatalf(`Crosswalk should not be called without humans`)
+	}
+
+	// The call stays until the lane turns GREEN
+	lane.PressButton()
+	lane.SetState(STATE_RED)
+	if !lane.IsCalled() {
+		t.Fatalf(`Crosswalk should be called after pressing the button`)
+	}
+	lane.SetState(STATE_GREEN)
+	if lane.IsCalled() {
+		t.Fatalf(`Crosswalk should not be called after turning GREEN`)
+	}
+	if lane.GetSignal() != SIGNAL_WALK {
+		t.Fatalf(`GREEN crosswalk should show %s, got %s`, SIGNAL_WALK, lane.GetSignal())
+	}
+}
@@ -2,28 +2,56 @@ package road
 
 import (
 	"log"
+	"math"
 	"math/rand"
 
-	"github.com/gookit/event"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
 	"github.com/martijnwiekens/go-learning/gointersection/traffic"
 )
 
+// Buses arrive at most this amount of ticks before or after their schedule
+const MAX_BUS_LATENESS int = 30
+
 type Road struct {
-	name             string  // Location of the road in the intersection NORTH, SOUTH, EAST, WEST, OUTPUT
-	lanes            []*Lane // Lanes of the road
-	newCarSpeed      uint8   // How fast new cars are coming in the road
-	newHumanSpeed    uint8   // How fast new humans are coming in the road
-	newBycycleSpeed  uint8   // How fast new bycycles are coming in the road
-	crossWalkEnabled bool    // Whether or not the road has a crosswalk
-	bycyclesEnabled  bool    // Whether or not the road has bycycles
+	name             string     // Location of the road in the intersection NORTH, SOUTH, EAST, WEST, OUTPUT
+	lanes            []*Lane    // Lanes of the road
+	newCarSpeed      uint8      // How fast new cars are coming in the road
+	newHumanSpeed    uint8      // How fast new humans are coming in the road
+	newBycycleSpeed  uint8      // How fast new bycycles are coming in the road
+	emergencySpeed   uint16     // Ticks between new emergency vehicles, 0 for none
+	busSpeed         uint16     // Ticks between new buses, 0 for none
+	crossWalkEnabled bool       // Whether or not the road has a crosswalk
+	bycyclesEnabled  bool       // Whether or not the road has bycycles
+	random           *rand.Rand // Random source of the simulation
+
+	// Events of the intersection, the road listens for its own empty lanes
+	events  *events.Bus
+	emptied *events.Subscription
 }
 
 type Lane struct {
-	road            string    // The road this lane belongs to
-	waitingTraffic  []Traffic // Traffic that is waiting in the lane
-	direction       string    // Direction of the lane LEFT, RIGHT, FORWARD, ALL
-	state           string    // State of the lane RED, ORANGE, GREEN
-	notifiedTraffic bool      // Whether or not the road has been notified of much traffic to the traffic controller
+	road            string            // The road this lane belongs to
+	waitingTraffic  []Traffic         // Traffic that is waiting in the lane
+	direction       string            // Direction of the lane LEFT, RIGHT, FORWARD, ALL
+	state           LightState        // State of the traffic light of the lane
+	stateTick       int               // Tick the light changed to the current state
+	currentTick     int               // Last tick of the lane
+	timing          SignalTiming      // Clearance times of the traffic light
+	notifiedTraffic bool              // Whether or not the road has been notified of much traffic to the traffic controller
+	random          *rand.Rand        // Random source of the simulation
+	metrics         *metrics.Recorder // Delay, queue and green time of the lane
+	departedTraffic []Departure       // Traffic that crossed this tick and has to go to another road
+	called          bool              // Whether or not the button of a crosswalk or bicycle lane was pressed
+	exitedTraffic   []Traffic         // Traffic that left the intersection on the OUTPUT lane this tick
+	events          *events.Bus       // Events of the intersection
+}
+
+// Departure is traffic that crossed the intersection on its way to the OUTPUT lane of another road
+type Departure struct {
+	Traffic     Traffic
+	Origin      string
+	Destination string
 }
 
 type Traffic interface {
@@ -31,6 +59,12 @@ type Traffic interface {
 	CrossRoad()
 }
 
+// Routed is traffic that drives to another road, like cars and buses
+type Routed interface {
+	GetRoute() *traffic.Route
+	SetFirstTick(tick int)
+}
+
 type NewRoadInput struct {
 	Name             string
 	Left             uint8
@@ -42,9 +76,19 @@ type NewRoadInput struct {
 	NewCarSpeed      uint8
 	NewHumanSpeed    uint8
 	NewBycycleSpeed  uint8
+	EmergencySpeed   uint16       // Ticks between new emergency vehicles, 0 for none
+	BusSpeed         uint16       // Ticks between new buses, 0 for none
+	Random           *rand.Rand   // Random source, leave empty for a random seed
+	SignalTiming     SignalTiming // Clearance times of the traffic lights
 }
 
 func NewRoad(input NewRoadInput) *Road {
+	// Use the same random source everywhere, so a seed repeats the same run
+	random := input.Random
+	if random == nil {
+		random = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	// Create the lanes of the road
 	var lanes []*Lane
 
@@ -53,7 +97,8 @@ func NewRoad(input NewRoadInput) *Road {
 		road:           input.Name,
 		waitingTraffic: []Traffic{},
 		direction:      "OUTPUT",
-		state:          "FLASH",
+		state:          STATE_FLASH,
+		timing:         input.SignalTiming,
 	})
 
 	// Create left lanes
@@ -62,7 +107,8 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "LEFT",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 
@@ -72,7 +118,8 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "FORWARD",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 
@@ -82,7 +129,8 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "ALL",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 
@@ -92,7 +140,8 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "RIGHT",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 
@@ -102,7 +151,8 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "CROSSWALK",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 	if input.BycyclesEnabled {
@@ -110,19 +160,20 @@ func NewRoad(input NewRoadInput) *Road {
 			road:           input.Name,
 			waitingTraffic: []Traffic{},
 			direction:      "BICYCLE",
-			state:          "FLASH",
+			state:          STATE_FLASH,
+			timing:         input.SignalTiming,
 		})
 	}
 
 	// Check if we have speed
 	if input.NewCarSpeed == 0 {
-		input.NewCarSpeed = uint8(rand.Intn(15))
+		input.NewCarSpeed = uint8(random.Intn(15))
 	}
 	if input.NewHumanSpeed == 0 {
-		input.NewHumanSpeed = uint8(rand.Intn(60))
+		input.NewHumanSpeed = uint8(random.Intn(60))
 	}
 	if input.NewBycycleSpeed == 0 {
-		input.NewBycycleSpeed = uint8(rand.Intn(15))
+		input.NewBycycleSpeed = uint8(random.Intn(15))
 	}
 
 	// Create the road
@@ -132,23 +183,19 @@ func NewRoad(input NewRoadInput) *Road {
 		newCarSpeed:      max(5, input.NewCarSpeed),
 		newHumanSpeed:    max(10, input.NewHumanSpeed),
 		newBycycleSpeed:  max(5, input.NewBycycleSpeed),
+		emergencySpeed:   input.EmergencySpeed,
+		busSpeed:         input.BusSpeed,
 		crossWalkEnabled: input.CrossWalkEnabled,
 		bycyclesEnabled:  input.BycyclesEnabled,
+		random:           random,
+	}
+	for _, lane := range lanes {
+		lane.random = random
+		lane.metrics = metrics.NewRecorder()
 	}
 
-	// Handle empty road
-	event.On("gointersection-road-empty", event.ListenerFunc(func(e event.Event) error {
-		roadName := e.Get("road").(string)
-		laneName := e.Get("lane").(string)
-		if roadName == r.name {
-			lanes := r.GetLanesByName(laneName)
-			if len(lanes) > 0 {
-				lanes[0].notifiedTraffic = false
-			}
-		}
-		return nil
-	}), event.Normal)
-
+	// The road has its own events until it is part of an intersection
+	r.SetEvents(events.NewBus())
 	return r
 }
 
@@ -156,6 +203,27 @@ func (r *Road) GetName() string {
 	return r.name
 }
 
+// SetEvents publishes the events of the road on the bus of the intersection
+func (r *Road) SetEvents(bus *events.Bus) {
+	if r.emptied != nil {
+		r.emptied.Unsubscribe()
+	}
+	r.events = bus
+	for _, lane := range r.lanes {
+		lane.events = bus
+	}
+
+	// Handle empty road
+	r.emptied = events.Subscribe(bus, func(e events.LaneEmptied) {
+		if e.Road == r.name {
+			lanes := r.GetLanesByName(e.Lane)
+			if len(lanes) > 0 {
+				lanes[0].notifiedTraffic = false
+			}
+		}
+	})
+}
+
 func (r *Road) GetLanes() []*Lane {
 	return r.lanes
 }
@@ -181,18 +249,19 @@ func (r *Road) GetLanesByName(name string) []*Lane {
 func (r *Road) Tick(currentTick int) {
 	// One loop
 	if currentTick%int(r.newCarSpeed) == 0 {
-		// Choose a random lane
-		laneIndex := max(1, rand.Intn(int(r.GetLanesCount())))
-		lane := r.GetLane(uint8(laneIndex))
+		// Choose a random lane for cars, the first lane is the OUTPUT lane
+		carLanes := r.getCarLanes()
+		laneIndex := max(1, r.random.Intn(len(carLanes)))
+		lane := carLanes[laneIndex]
 
 		// Add new cars, there is a 10% chance of a double car
 		var amountCars uint8 = 1
-		if rand.Intn(5) == 0 {
-			amountCars = uint8(rand.Intn(10))
+		if r.random.Intn(5) == 0 {
+			amountCars = uint8(r.random.Intn(10))
 		}
 		log.Default().Println("R: +", amountCars, "cars incoming at", r.name, ":", lane.direction)
 		for i := uint8(0); i < amountCars; i++ {
-			lane.waitingTraffic = append(lane.waitingTraffic, &traffic.Car{FirstTick: currentTick})
+			lane.addTraffic(&traffic.Car{FirstTick: currentTick, Route: lane.newRoute()})
 		}
 	}
 	if r.crossWalkEnabled && currentTick%int(r.newHumanSpeed) == 0 {
@@ -200,7 +269,7 @@ func (r *Road) Tick(currentTick int) {
 		lanes := r.GetLanesByName("CROSSWALK")
 		if len(lanes) > 0 {
 			log.Default().Println("R: +1 human incoming at", r.name, ":CROSSWALK")
-			lanes[0].waitingTraffic = append(lanes[0].waitingTraffic, &traffic.Human{FirstTick: currentTick})
+			lanes[0].addTraffic(&traffic.Human{FirstTick: currentTick})
 		}
 	}
 	if r.bycyclesEnabled && currentTick%int(r.newBycycleSpeed) == 0 {
@@ -208,25 +277,141 @@ func (r *Road) Tick(currentTick int) {
 		lanes := r.GetLanesByName("BICYCLE")
 		if len(lanes) > 0 {
 			log.Default().Println("R: +1 bicycle incoming at", r.name, ":BICYCLE")
-			lanes[0].waitingTraffic = append(lanes[0].waitingTraffic, &traffic.Bycycle{FirstTick: currentTick})
+			lanes[0].addTraffic(&traffic.Bycycle{FirstTick: currentTick})
 		}
 	}
 
-	// Check how many items in the lane
+	if r.emergencySpeed > 0 && currentTick > 0 && currentTick%int(r.emergencySpeed) == 0 {
+		// Create a new emergency vehicle on a random lane for cars
+		carLanes := r.getCarLanes()
+		if len(carLanes) > 1 {
+			carLanes[max(1, r.random.Intn(len(carLanes)))].AddEmergencyVehicle(currentTick)
+		}
+	}
+
+	if r.busSpeed > 0 && currentTick > 0 && currentTick%int(r.busSpeed) == 0 {
+		// Create a new bus on a random lane for cars, it can be early or late
+		carLanes := r.getCarLanes()
+		if len(carLanes) > 1 {
+			lateness := r.random.Intn(2*MAX_BUS_LATENESS+1) - MAX_BUS_LATENESS
+			carLanes[max(1, r.random.Intn(len(carLanes)))].AddBus(currentTick, lateness)
+		}
+	}
+
+	// Check how many items in the lane, the OUTPUT lane has no traffic light
 	for _, lane := range r.GetLanes() {
+		if lane.direction == "OUTPUT" {
+			continue
+		}
 		if lane.GetWaitingTrafficCount() > 3 && !lane.notifiedTraffic {
 			lane.notifiedTraffic = true
-			event.MustFire("gointersection-road-traffic", event.M{"road": r.name, "lane": lane.direction})
+			r.events.Publish(events.LaneCongested{Tick: currentTick, Road: r.name, Lane: lane.direction})
 			break
 		}
 	}
 
 	// Tick each of the lanes
 	for _, lane := range r.GetLanes() {
-		lane.Tick()
+		lane.Tick(currentTick)
 	}
 }
 
+// getCarLanes returns the OUTPUT lane and the lanes cars drive in, without the crosswalk and bicycle lane
+func (r *Road) getCarLanes() []*Lane {
+	var lanes []*Lane
+	for _, lane := range r.lanes {
+		if !IsCrossingLane(lane.direction) {
+			lanes = append(lanes, lane)
+		}
+	}
+	return lanes
+}
+
+// AddOutputTraffic puts traffic that crossed the intersection on the OUTPUT lane
+func (r *Road) AddOutputTraffic(t Traffic) {
+	lanes := r.GetLanesByName("OUTPUT")
+	if len(lanes) > 0 {
+		lanes[0].addTraffic(t)
+	}
+}
+
+// TakeDepartedTraffic returns the traffic that crossed the intersection since the last call
+func (r *Road) TakeDepartedTraffic() []Departure {
+	var departures []Departure
+	for _, lane := range r.lanes {
+		departures = append(departures, lane.departedTraffic...)
+		lane.departedTraffic = nil
+	}
+	return departures
+}
+
+// MOVEMENTS are the ways traffic in an ALL lane can drive
+var MOVEMENTS = []string{"LEFT", "FORWARD", "RIGHT"}
+
+// TakeExitedTraffic returns the traffic that left the intersection on the OUTPUT lane since the last call
+func (r *Road) TakeExitedTraffic() []Traffic {
+	var exited []Traffic
+	for _, lane := range r.lanes {
+		exited = append(exited, lane.exitedTraffic...)
+		lane.exitedTraffic = nil
+	}
+	return exited
+}
+
+// AddIncomingTraffic puts a vehicle that comes from another intersection on a random lane for cars.
+// It gets a new route for this intersection, the stops of its trip are kept.
+func (r *Road) AddIncomingTraffic(t Traffic, currentTick int) {
+	carLanes := r.getCarLanes()
+	if len(carLanes) < 2 {
+		return
+	}
+	lane := carLanes[max(1, r.random.Intn(len(carLanes)))]
+	if routed, ok := t.(Routed); ok {
+		route := routed.GetRoute()
+		newRoute := lane.newRoute()
+		newRoute.Stops = route.Stops
+		newRoute.Intersections = route.Intersections
+		*route = newRoute
+		routed.SetFirstTick(currentTick)
+	}
+	lane.addTraffic(t)
+	lane.notifyArrival(t)
+}
+
+// GetDestinations returns the roads traffic in a lane can drive to, traffic drives on the right.
+// Crosswalks and bicycle lanes don't end on another road.
+func GetDestinations(roadName string, laneName string) []string {
+	if laneName == "ALL" {
+		var destinations []string
+		for _, movement := range MOVEMENTS {
+			destinations = append(destinations, GetDestination(roadName, movement))
+		}
+		return destinations
+	}
+	destination := GetDestination(roadName, laneName)
+	if destination == "" {
+		return nil
+	}
+	return []string{destination}
+}
+
+// GetDestination returns the road a LEFT, FORWARD or RIGHT movement ends on, or an empty string for other lanes
+func GetDestination(roadName string, movement string) string {
+	// Traffic from the NORTH drives to the SOUTH, so a left turn ends on the EAST
+	forward := map[string]string{"NORTH": "SOUTH", "EAST": "WEST", "SOUTH": "NORTH", "WEST": "EAST"}
+	left := map[string]string{"NORTH": "EAST", "EAST": "SOUTH", "SOUTH": "WEST", "WEST": "NORTH"}
+	right := map[string]string{"NORTH": "WEST", "EAST": "NORTH", "SOUTH": "EAST", "WEST": "SOUTH"}
+	switch movement {
+	case "LEFT":
+		return left[roadName]
+	case "FORWARD":
+		return forward[roadName]
+	case "RIGHT":
+		return right[roadName]
+	}
+	return ""
+}
+
 func (r *Road) GetLongestWaitingTraffic(laneName string) int {
 	var longest int = 0
 	lanes := r.GetLanesByName(laneName)
@@ -242,44 +427,156 @@ func (l *Lane) GetWaitingTrafficCount() int {
 	return len(l.waitingTraffic)
 }
 
+// GetArrivedTrafficCount returns the traffic that arrived in the lane since the start, like a detector in the road
+func (l *Lane) GetArrivedTrafficCount() int {
+	return l.metrics.GetArrived()
+}
+
 func (l *Lane) GetDirection() string {
 	return l.direction
 }
 
-func (l *Lane) GetState() string {
+func (l *Lane) GetState() LightState {
 	return l.state
 }
 
-func (l *Lane) SetState(state string) {
-	// Update state
+// GetTicksInState returns how long the light has the current state
+func (l *Lane) GetTicksInState() int {
+	return l.currentTick - l.stateTick
+}
+
+func (l *Lane) GetSignalTiming() SignalTiming {
+	return l.timing
+}
+
+// CanSetState returns why the light can't change to the state, or nil when it can
+func (l *Lane) CanSetState(state LightState) error {
+	return CheckTransition(l.state, state, l.GetTicksInState(), l.timing)
+}
+
+// SetState changes the light when the transition is allowed
+func (l *Lane) SetState(state LightState) error {
+	err := l.CanSetState(state)
+	if err != nil {
+		return err
+	}
+	l.ForceState(state)
+	return nil
+}
+
+// ForceState changes the light without checking the transition, only for resetting the intersection
+func (l *Lane) ForceState(state LightState) {
+	// Count the green phases
+	if state == STATE_GREEN && l.state != STATE_GREEN {
+		l.metrics.RecordGreenPhase()
+	}
+
+	// The call is served when the lane turns GREEN
+	if state == STATE_GREEN {
+		l.called = false
+	}
+
+	// Update state, the waiting traffic has to stop when the light is no longer GREEN
+	if state == l.state {
+		return
+	}
+	l.stateTick = l.currentTick
+	if l.direction != "OUTPUT" && isStopState(state) && !isStopState(l.state) {
+		for _, t := range l.waitingTraffic {
+			stop(t)
+		}
+	}
+	from := l.state
 	l.state = state
+
+	// The OUTPUT lane has no traffic light
+	if l.direction != "OUTPUT" {
+		l.events.Publish(events.LightChanged{Tick: l.currentTick, Road: l.road, Lane: l.direction, From: string(from), State: string(state)})
+	}
 }
 
-func (l *Lane) Tick() {
-	if l.GetState() == "FLASH" || l.GetState() == "GREEN" {
+func (l *Lane) Tick(currentTick int) {
+	// Remember the tick, the clearance times are counted in ticks
+	l.currentTick = currentTick
+
+	// Remember the queue
+	l.metrics.RecordTick(len(l.waitingTraffic), l.GetState() == STATE_GREEN)
+
+	// The OUTPUT lane has no traffic light, traffic always leaves
+	if l.direction == "OUTPUT" || l.GetState() == STATE_FLASH || l.GetState() == STATE_OFF || l.GetState() == STATE_GREEN {
 		// Let out some traffic
 		if len(l.waitingTraffic) > 0 {
 			// There is a 20% chance of letting double cars out
 			var amountCars uint8 = 1
-			if rand.Intn(20) == 0 {
+			if l.random.Intn(20) == 0 {
 				amountCars = 2
 			}
+
+			// Humans cross together when the crosswalk shows WALK
+			if l.direction == "CROSSWALK" && l.GetState() == STATE_GREEN {
+				amountCars = uint8(min(len(l.waitingTraffic), math.MaxUint8))
+			}
+
+			// Bicycles ride next to each other
+			if l.direction == "BICYCLE" && l.GetState() == STATE_GREEN {
+				amountCars = BICYCLES_PER_TICK
+			}
 			log.Default().Println("R: -", amountCars, "cars leaving on", l.road, ":", l.direction)
 			for i := uint8(0); i < amountCars; i++ {
 				if len(l.waitingTraffic) > 0 {
+					delay := currentTick - l.waitingTraffic[0].GetFirstTick()
+					l.metrics.RecordCrossing(delay)
+					if _, ok := l.waitingTraffic[0].(*traffic.Bus); ok {
+						l.metrics.RecordBusCrossing(delay)
+					}
 					l.waitingTraffic[0].CrossRoad()
+					l.depart(l.waitingTraffic[0])
 					l.waitingTraffic = l.waitingTraffic[1:]
+					if l.direction != "OUTPUT" {
+						l.events.Publish(events.VehicleCrossed{Tick: currentTick, Road: l.road, Lane: l.direction, Delay: delay})
+					}
 				}
 			}
 
 			// Check if we have traffic
-			if len(l.waitingTraffic) == 0 {
-				event.MustFire("gointersection-road-empty", event.M{"road": l.road, "lane": l.direction})
+			if len(l.waitingTraffic) == 0 && l.direction != "OUTPUT" {
+				l.events.Publish(events.LaneEmptied{Tick: currentTick, Road: l.road, Lane: l.direction})
 			}
 		}
 	}
 }
 
+func (l *Lane) depart(t Traffic) {
+	// Humans and bicycles don't drive to another road
+	routed, ok := t.(Routed)
+	if !ok {
+		return
+	}
+
+	// Traffic on the OUTPUT lane leaves the intersection, in a network it drives to the next one
+	if l.direction == "OUTPUT" {
+		l.exitedTraffic = append(l.exitedTraffic, t)
+		return
+	}
+
+	// Count the intersection and the stop for the trip of the vehicle
+	route := routed.GetRoute()
+	route.Intersections++
+	if route.Stopped {
+		l.metrics.RecordStop()
+	}
+	l.departedTraffic = append(l.departedTraffic, Departure{Traffic: t, Origin: route.Origin, Destination: route.Destination})
+}
+
+// newRoute picks the movement of a new vehicle, traffic in an ALL lane can drive every way
+func (l *Lane) newRoute() traffic.Route {
+	movement := l.direction
+	if movement == "ALL" {
+		movement = MOVEMENTS[l.random.Intn(len(MOVEMENTS))]
+	}
+	return traffic.Route{Origin: l.road, Movement: movement, Destination: GetDestination(l.road, movement)}
+}
+
 func (l *Lane) GetRoad() string {
 	return l.road
 }
@@ -294,3 +591,104 @@ func (l *Lane) GetLongestWaitingTraffic() int {
 func (l *Lane) GetNotified() bool {
 	return l.notifiedTraffic
 }
+
+// AddEmergencyVehicle puts an emergency vehicle at the back of the lane and asks the traffic controller for GREEN
+func (l *Lane) AddEmergencyVehicle(currentTick int) {
+	log.Default().Println("R: +1 emergency vehicle incoming at", l.road, ":", l.direction)
+	vehicle := &traffic.EmergencyVehicle{FirstTick: currentTick, Route: l.newRoute()}
+	l.addTraffic(vehicle)
+	l.notifyArrival(vehicle)
+}
+
+// HasEmergencyVehicle returns whether or not an emergency vehicle waits in the lane
+func (l *Lane) HasEmergencyVehicle() bool {
+	for _, t := range l.waitingTraffic {
+		if _, ok := t.(*traffic.EmergencyVehicle); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBus puts a bus at the back of the lane and tells the traffic controller how late it is
+func (l *Lane) AddBus(currentTick int, lateness int) {
+	log.Default().Println("R: +1 bus incoming at", l.road, ":", l.direction, "with a lateness of", lateness, "ticks")
+	bus := &traffic.Bus{FirstTick: currentTick, Lateness: lateness, Route: l.newRoute()}
+	l.addTraffic(bus)
+	l.notifyArrival(bus)
+}
+
+// HasBus returns whether or not a bus waits in the lane
+func (l *Lane) HasBus() bool {
+	for _, t := range l.waitingTraffic {
+		if _, ok := t.(*traffic.Bus); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Lane) addTraffic(t Traffic) {
+	l.waitingTraffic = append(l.waitingTraffic, t)
+	l.metrics.RecordArrival()
+
+	// Traffic that arrives at a light that is not GREEN has to stop
+	if l.direction != "OUTPUT" && isStopState(l.state) {
+		stop(t)
+	}
+}
+
+// notifyArrival tells the traffic controller about an emergency vehicle or a bus in the lane
+func (l *Lane) notifyArrival(t Traffic) {
+	switch vehicle := t.(type) {
+	case *traffic.EmergencyVehicle:
+		l.events.Publish(events.EmergencyVehicleArrived{Tick: t.GetFirstTick(), Road: l.road, Lane: l.direction})
+	case *traffic.Bus:
+		l.events.Publish(events.BusArrived{Tick: t.GetFirstTick(), Road: l.road, Lane: l.direction, Lateness: vehicle.GetLateness()})
+	}
+}
+
+// isStopState returns whether or not traffic has to stop for the light
+func isStopState(state LightState) bool {
+	return state == STATE_RED || state == STATE_RED_ORANGE || state == STATE_ORANGE
+}
+
+// stop counts a stop for traffic that has a route, humans and bicycles are not counted
+func stop(t Traffic) {
+	if routed, ok := t.(Routed); ok {
+		routed.GetRoute().Stop()
+	}
+}
+
+func (l *Lane) GetMetrics() metrics.Summary {
+	return l.metrics.GetSummary()
+}
+
+func (l *Lane) GetDelayHistogram() metrics.Histogram {
+	return l.metrics.GetDelayHistogram()
+}
+
+func (r *Road) GetMetrics() metrics.Summary {
+	return metrics.Summarize(r.getRecorders()...)
+}
+
+func (r *Road) getRecorders() []*metrics.Recorder {
+	var recorders []*metrics.Recorder
+	for _, lane := range r.lanes {
+		// Traffic on the OUTPUT lane was already counted on the lane it came from
+		if lane.direction == "OUTPUT" {
+			continue
+		}
+		recorders = append(recorders, lane.metrics)
+	}
+	return recorders
+}
+
+// GetMetricsOfRoads combines the metrics of multiple roads, like the whole intersection
+func GetMetricsOfRoads(roads []*Road) metrics.Summary {
+	var recorders []*metrics.Recorder
+	for _, r := range roads {
+		recorders = append(recorders, r.getRecorders()...)
+	}
+	return metrics.Summarize(recorders...)
+}
@@ -0,0 +1,122 @@
+package road
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/traffic"
+)
+
+func runRoad(seed int64, ticks int) []int {
+	r := NewRoad(NewRoadInput{
+		Name:             "NORTH",
+		Left:             1,
+		Forward:          2,
+		Right:            1,
+		CrossWalkEnabled: true,
+		BycyclesEnabled:  true,
+		Random:           rand.New(rand.NewSource(seed)),
+	})
+
+	// Keep the lights red, so the traffic piles up
+	for _, lane := range r.GetLanes() {
+		lane.SetState("RED")
+	}
+
+	// Remember the waiting traffic after every tick
+	var waiting []int
+	for tick := 0; tick < ticks; tick++ {
+		r.Tick(tick)
+		for _, lane := range r.GetLanes() {
+			waiting = append(waiting, lane.GetWaitingTrafficCount())
+		}
+	}
+	return waiting
+}
+
+func TestSameSeedSameTraffic(t *testing.T) {
+	first := runRoad(42, 500)
+	second := runRoad(42, 500)
+	for index := range first {
+		if first[index] != second[index] {
+			t.Fatalf(`Traffic is different with the same seed at index %d: %d and %d`, index, first[index], second[index])
+		}
+	}
+
+	// Another seed should give other traffic
+	other := runRoad(43, 500)
+	same := true
+	for index := range first {
+		if first[index] != other[index] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Fatalf(`Traffic is the same with another seed`)
+	}
+}
+
+func TestRouteMatchesLane(t *testing.T) {
+	r := NewRoad(NewRoadInput{Name: "EAST", Left: 1, All: 1, NewCarSpeed: 5, Random: rand.New(rand.NewSource(42))})
+	for _, lane := range r.GetLanes() {
+		lane.SetState("RED")
+	}
+	for tick := 0; tick < 500; tick++ {
+		r.Tick(tick)
+	}
+
+	// Every car drives to the road of its movement, cars in the ALL lane drive every way
+	movements := map[string]bool{}
+	for _, lane := range r.GetLanes() {
+		for _, waiting := range lane.waitingTraffic {
+			route := waiting.(*traffic.Car).GetRoute()
+			if route.Origin != "EAST" || route.Destination != GetDestination("EAST", route.Movement) {
+				t.Fatalf(`Route should start on EAST and end on the road of the movement, got %+v`, route)
+			}
+			if lane.direction != "ALL" && route.Movement != lane.direction {
+				t.Fatalf(`Car in EAST:%s should drive %s, got %s`, lane.direction, lane.direction, route.Movement)
+			}
+			if lane.direction == "ALL" {
+				movements[route.Movement] = true
+			}
+		}
+	}
+	if len(movements) != len(MOVEMENTS) {
+		t.Fatalf(`Cars in EAST:ALL should drive every way, got %v`, movements)
+	}
+}
+
+func TestTrafficStopsForTheLight(t *testing.T) {
+	r := NewRoad(NewRoadInput{Name: "WEST", Forward: 1, NewCarSpeed: 255, Random: rand.New(rand.NewSource(1))})
+	lane := r.GetLanesByName("FORWARD")[0]
+	lane.ForceState(STATE_GREEN)
+
+	// The first car drives on, the second car waits behind it when the light turns RED
+	first := &traffic.Car{Route: lane.newRoute()}
+	lane.addTraffic(first)
+	second := &traffic.Car{Route: lane.newRoute()}
+	lane.addTraffic(second)
+	lane.Tick(1)
+	lane.ForceState(STATE_ORANGE)
+	lane.ForceState(STATE_RED)
+
+	// The third car arrives at a RED light, every car stops once
+	third := &traffic.Car{Route: lane.newRoute()}
+	lane.addTraffic(third)
+	lane.ForceState(STATE_GREEN)
+	lane.ForceState(STATE_RED)
+	for index, car := range []*traffic.Car{first, second, third} {
+		if car.Stops != min(index, 1) {
+			t.Fatalf(`Car %d should stop %d times, got %+v`, index+1, min(index, 1), car.Route)
+		}
+	}
+
+	// The stop is counted when the car crosses
+	lane.ForceState(STATE_GREEN)
+	lane.Tick(2)
+	lane.Tick(3)
+	if lane.GetMetrics().Stops != 2 || first.Intersections != 1 {
+		t.Fatalf(`2 cars should cross after a stop, got %d`, lane.GetMetrics().Stops)
+	}
+}
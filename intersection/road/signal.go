@@ -0,0 +1,81 @@
+package road
+
+import (
+	"fmt"
+)
+
+// LightState is the state of the traffic light of a lane
+type LightState string
+
+const (
+	STATE_RED        LightState = "RED"
+	STATE_RED_ORANGE LightState = "RED-ORANGE" // Red and orange together before GREEN, only when configured
+	STATE_GREEN      LightState = "GREEN"
+	STATE_ORANGE     LightState = "ORANGE"
+	STATE_FLASH      LightState = "FLASH" // Flashing orange, traffic gives way on its own
+	STATE_OFF        LightState = "OFF"   // The light is dark
+)
+
+var LIGHT_STATES = []LightState{STATE_RED, STATE_RED_ORANGE, STATE_GREEN, STATE_ORANGE, STATE_FLASH, STATE_OFF}
+
+// LIGHT_TRANSITIONS are the states a light can go to from every state.
+// FLASH and OFF can be reached from every state, so a broken intersection can always be made safe.
+var LIGHT_TRANSITIONS = map[LightState][]LightState{
+	STATE_RED:        {STATE_RED_ORANGE, STATE_GREEN, STATE_FLASH, STATE_OFF},
+	STATE_RED_ORANGE: {STATE_GREEN, STATE_RED, STATE_FLASH, STATE_OFF},
+	STATE_GREEN:      {STATE_ORANGE, STATE_FLASH, STATE_OFF},
+	STATE_ORANGE:     {STATE_RED, STATE_FLASH, STATE_OFF},
+	STATE_FLASH:      {STATE_RED, STATE_OFF},
+	STATE_OFF:        {STATE_RED, STATE_FLASH},
+}
+
+const DEFAULT_MIN_ORANGE int = 1
+
+// SignalTiming are the clearance times of the lights in ticks
+type SignalTiming struct {
+	RedOrange int `json:"redOrange"` // Ticks of RED-ORANGE before GREEN, 0 goes from RED to GREEN
+	MinOrange int `json:"minOrange"` // Ticks a light stays ORANGE at least, 0 for the default
+	AllRed    int `json:"allRed"`    // Ticks a lane stays RED before a lane that crosses it turns GREEN
+}
+
+func (s SignalTiming) GetMinOrange() int {
+	if s.MinOrange == 0 {
+		return DEFAULT_MIN_ORANGE
+	}
+	return s.MinOrange
+}
+
+// CheckTransition returns why a light can't go from one state to the other, or nil when it can
+func CheckTransition(from LightState, to LightState, ticksInState int, timing SignalTiming) error {
+	// Nothing changes
+	if from == to {
+		return nil
+	}
+
+	// Check if the transition exists
+	if _, ok := LIGHT_TRANSITIONS[to]; !ok {
+		return fmt.Errorf("unknown state %q", to)
+	}
+	allowed := false
+	for _, state := range LIGHT_TRANSITIONS[from] {
+		if state == to {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("can't go from %s to %s", from, to)
+	}
+
+	// Check the clearance times
+	switch {
+	case from == STATE_RED && to == STATE_GREEN && timing.RedOrange > 0:
+		return fmt.Errorf("can't go from %s to %s, RED-ORANGE comes first", from, to)
+	case from == STATE_RED && to == STATE_RED_ORANGE && timing.RedOrange == 0:
+		return fmt.Errorf("can't go from %s to %s, RED-ORANGE is not configured", from, to)
+	case from == STATE_RED_ORANGE && to == STATE_GREEN && ticksInState < timing.RedOrange:
+		return fmt.Errorf("%s should last %d ticks, it lasted %d", from, timing.RedOrange, ticksInState)
+	case from == STATE_ORANGE && to == STATE_RED && ticksInState < timing.GetMinOrange():
+		return fmt.Errorf("%s should last %d ticks, it lasted %d", from, timing.GetMinOrange(), ticksInState)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package road
+
+import "testing"
+
+func TestCheckTransition(t *testing.T) {
+	timing := SignalTiming{MinOrange: 2}
+	redOrange := SignalTiming{RedOrange: 1}
+	tests := []struct {
+		from    LightState
+		to      LightState
+		ticks   int
+		timing  SignalTiming
+		allowed bool
+	}{
+		{STATE_RED, STATE_GREEN, 0, timing, true},
+		{STATE_GREEN, STATE_ORANGE, 0, timing, true},
+		{STATE_GREEN, STATE_RED, 5, timing, false},
+		{STATE_ORANGE, STATE_RED, 1, timing, false},
+		{STATE_ORANGE, STATE_RED, 2, timing, true},
+		{STATE_ORANGE, STATE_RED, 0, SignalTiming{}, false},
+		{STATE_ORANGE, STATE_GREEN, 5, timing, false},
+		{STATE_RED, STATE_ORANGE, 5, timing, false},
+		{STATE_RED, STATE_RED_ORANGE, 0, timing, false},
+		{STATE_RED, STATE_GREEN, 0, redOrange, false},
+		{STATE_RED, STATE_RED_ORANGE, 0, redOrange, true},
+		{STATE_RED_ORANGE, STATE_GREEN, 0, redOrange, false},
+		{STATE_RED_ORANGE, STATE_GREEN, 1, redOrange, true},
+		{STATE_GREEN, STATE_FLASH, 0, timing, true},
+		{STATE_ORANGE, STATE_OFF, 0, timing, true},
+		{STATE_FLASH, STATE_RED, 0, timing, true},
+		{STATE_FLASH, STATE_GREEN, 0, timing, false},
+		{STATE_GREEN, STATE_GREEN, 0, timing, true},
+		{STATE_RED, "GREEEN", 0, timing, false},
+	}
+	for _, test := range tests {
+		err := CheckTransition(test.from, test.to, test.ticks, test.timing)
+		if (err == nil) != test.allowed {
+			t.Fatalf(`%s to %s after %d ticks should be allowed %v, got %v`, test.from, test.to, test.ticks, test.allowed, err)
+		}
+	}
+}
+
+func TestLaneCountsTicksInState(t *testing.T) {
+	r := NewRoad(NewRoadInput{Name: "NORTH", Forward: 1, SignalTiming: SignalTiming{MinOrange: 3}})
+	lane := r.GetLanesByName("FORWARD")[0]
+	lane.SetState(STATE_RED)
+	lane.SetState(STATE_GREEN)
+	lane.SetState(STATE_ORANGE)
+
+	// The lane stays ORANGE for the min orange time
+	for tick := 1; tick <= 3; tick++ {
+		if lane.SetState(STATE_RED) == nil {
+			t.Fatalf(`ORANGE should last 3 ticks, turned RED after %d`, lane.GetTicksInState())
+		}
+		r.Tick(tick)
+	}
+	if err := lane.SetState(STATE_RED); err != nil {
+		t.Fatalf(`ORANGE should turn RED after 3 ticks, got %v`, err)
+	}
+}
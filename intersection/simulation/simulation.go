@@ -0,0 +1,68 @@
+package simulation
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock keeps the current tick of the simulation and decides how long a tick takes
+type Clock interface {
+	GetCurrentTick() int
+	Now() time.Time // Simulated time of the current tick
+	Next()          // Wait for the next tick
+}
+
+type RealClock struct {
+	tickSpeed   time.Duration
+	start       time.Time
+	currentTick int
+}
+
+// VirtualClock never waits, so the simulation runs as fast as the computer can
+type VirtualClock struct {
+	tickSpeed   time.Duration
+	start       time.Time
+	currentTick int
+}
+
+func NewRealClock(tickSpeed time.Duration) *RealClock {
+	return &RealClock{tickSpeed: tickSpeed, start: time.Now()}
+}
+
+func (c *RealClock) GetCurrentTick() int {
+	return c.currentTick
+}
+
+func (c *RealClock) Now() time.Time {
+	return c.start.Add(time.Duration(c.currentTick) * c.tickSpeed)
+}
+
+func (c *RealClock) Next() {
+	time.Sleep(c.tickSpeed)
+	c.currentTick++
+}
+
+func NewVirtualClock(tickSpeed time.Duration, start time.Time) *VirtualClock {
+	return &VirtualClock{tickSpeed: tickSpeed, start: start}
+}
+
+func (c *VirtualClock) GetCurrentTick() int {
+	return c.currentTick
+}
+
+func (c *VirtualClock) Now() time.Time {
+	return c.start.Add(time.Duration(c.currentTick) * c.tickSpeed)
+}
+
+func (c *VirtualClock) Next() {
+	c.currentTick++
+}
+
+// NewRandom creates the random source of a run, a seed of 0 picks a new seed.
+// The seed is returned, so the run can be repeated.
+func NewRandom(seed int64) (*rand.Rand, int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed)), seed
+}
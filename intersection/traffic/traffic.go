@@ -1,7 +1,20 @@
 package traffic
 
+// Route is where a vehicle comes from and where it drives to
+type Route struct {
+	Origin      string // Road the vehicle arrived on
+	Movement    string // LEFT, FORWARD or RIGHT
+	Destination string // Road the vehicle drives to, it ends on the OUTPUT lane of this road
+	Stopped     bool   // Whether or not the vehicle stopped for the light of this intersection
+
+	// The trip of the vehicle through all intersections, a corridor has more than one
+	Stops         int // Times the vehicle stopped for a light
+	Intersections int // Intersections the vehicle crossed
+}
+
 type Car struct {
 	FirstTick int
+	Route
 }
 
 type Human struct {
@@ -12,6 +25,31 @@ type Bycycle struct {
 	FirstTick int
 }
 
+// EmergencyVehicle is an ambulance, fire truck or police car, the traffic controller gives its lane GREEN
+type EmergencyVehicle struct {
+	FirstTick int
+	Route
+}
+
+// Bus drives on a schedule, the traffic controller gives priority to a bus that is late
+type Bus struct {
+	FirstTick int
+	Lateness  int // Ticks the bus is behind its schedule, negative when it is early
+	Route
+}
+
+func (r *Route) GetRoute() *Route {
+	return r
+}
+
+// Stop counts a stop of the vehicle, it stops once for every intersection
+func (r *Route) Stop() {
+	if !r.Stopped {
+		r.Stopped = true
+		r.Stops++
+	}
+}
+
 func (c *Car) GetFirstTick() int {
 	return c.FirstTick
 }
@@ -24,6 +62,31 @@ func (b *Bycycle) GetFirstTick() int {
 	return b.FirstTick
 }
 
+func (e *EmergencyVehicle) GetFirstTick() int {
+	return e.FirstTick
+}
+
+func (b *Bus) GetFirstTick() int {
+	return b.FirstTick
+}
+
+func (b *Bus) GetLateness() int {
+	return b.Lateness
+}
+
+// SetFirstTick is called when the vehicle arrives at the next intersection
+func (c *Car) SetFirstTick(tick int) {
+	c.FirstTick = tick
+}
+
+func (e *EmergencyVehicle) SetFirstTick(tick int) {
+	e.FirstTick = tick
+}
+
+func (b *Bus) SetFirstTick(tick int) {
+	b.FirstTick = tick
+}
+
 func (c *Car) CrossRoad() {
 	// Cross the road
 }
@@ -35,3 +98,11 @@ func (h *Human) CrossRoad() {
 func (b *Bycycle) CrossRoad() {
 	// Cross the road
 }
+
+func (e *EmergencyVehicle) CrossRoad() {
+	// Cross the road
+}
+
+func (b *Bus) CrossRoad() {
+	// Cross the road
+}
@@ -0,0 +1,199 @@
+package trafficcontroller
+
+import (
+	"fmt"
+	"log"
+)
+
+type ActuatedPhase struct {
+	Lanes      []*TrafficPattern // Lanes that turn GREEN together
+	MinGreen   int               // Ticks the light stays GREEN at least
+	MaxGreen   int               // Ticks the light stays GREEN at most
+	GapTime    int               // Ticks without traffic before the GREEN ends
+	OrangeTime int               // Ticks the light stays ORANGE
+}
+
+// ACTUATED_PHASES are the phases of TRAFFIC_PATTERN with their own timing, change them with ActuatedTiming in the config.
+// A short min green and gap time let a phase end as soon as its queue is gone, so the other phases wait less.
+var ACTUATED_PHASES []*ActuatedPhase = []*ActuatedPhase{
+	{Lanes: TRAFFIC_PATTERN[0], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[1], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[2], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[3], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[4], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[5], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[6], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[7], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[8], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[9], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+	{Lanes: TRAFFIC_PATTERN[10], MinGreen: 1, MaxGreen: 20, GapTime: 1, OrangeTime: 1},
+}
+
+// ActuatedTiming changes the timing of a phase of ACTUATED_PHASES in the config, 0 keeps the default
+type ActuatedTiming struct {
+	Phase      int `json:"phase"`      // Index of the phase in TRAFFIC_PATTERN
+	MinGreen   int `json:"minGreen"`   // Ticks the light stays GREEN at least
+	MaxGreen   int `json:"maxGreen"`   // Ticks the light stays GREEN at most
+	GapTime    int `json:"gapTime"`    // Ticks without traffic before the GREEN ends
+	OrangeTime int `json:"orangeTime"` // Ticks the light stays ORANGE
+}
+
+// GetActuatedPhases returns a copy of ACTUATED_PHASES with the timings of the config, check them with ValidateActuatedTimings first
+func GetActuatedPhases(timings []ActuatedTiming) []*ActuatedPhase {
+	var phases []*ActuatedPhase
+	for _, phase := range ACTUATED_PHASES {
+		newPhase := *phase
+		phases = append(phases, &newPhase)
+	}
+	for _, timing := range timings {
+		phase := phases[timing.Phase]
+		if timing.MinGreen != 0 {
+			phase.MinGreen = timing.MinGreen
+		}
+		if timing.MaxGreen != 0 {
+			phase.MaxGreen = timing.MaxGreen
+		}
+		if timing.GapTime != 0 {
+			phase.GapTime = timing.GapTime
+		}
+		if timing.OrangeTime != 0 {
+			phase.OrangeTime = timing.OrangeTime
+		}
+	}
+	return phases
+}
+
+// ValidateActuatedTimings returns an error for every timing that can't be used, the errors start with the index of the timing
+func ValidateActuatedTimings(timings []ActuatedTiming) []error {
+	var errs []error
+	seen := map[int]bool{}
+	for index, timing := range timings {
+		// Check the phase
+		if timing.Phase < 0 || timing.Phase >= len(ACTUATED_PHASES) {
+			errs = append(errs, fmt.Errorf("[%d].phase: unknown phase %d, expected 0 to %d", index, timing.Phase, len(ACTUATED_PHASES)-1))
+			continue
+		}
+		if seen[timing.Phase] {
+			errs = append(errs, fmt.Errorf("[%d].phase: phase %d has more than one timing", index, timing.Phase))
+		}
+		seen[timing.Phase] = true
+
+		// Check the ticks
+		names := []string{"minGreen", "maxGreen", "gapTime", "orangeTime"}
+		for field, ticks := range []int{timing.MinGreen, timing.MaxGreen, timing.GapTime, timing.OrangeTime} {
+			if ticks < 0 {
+				errs = append(errs, fmt.Errorf("[%d].%s: should be 0 or more, got %d", index, names[field], ticks))
+			}
+		}
+
+		// The max green can't end the GREEN before the min green
+		phase := GetActuatedPhases([]ActuatedTiming{timing})[timing.Phase]
+		if timing.MinGreen >= 0 && timing.MaxGreen >= 0 && phase.MaxGreen < phase.MinGreen {
+			errs = append(errs, fmt.Errorf("[%d].maxGreen: should be at least the min green of %d, got %d", index, phase.MinGreen, phase.MaxGreen))
+		}
+	}
+	return errs
+}
+
+// ActuatedStrategy keeps a phase GREEN while traffic keeps coming, like a signal with detectors in the road.
+// A phase ends when no traffic was seen for the gap time (gap-out) or when the max green is reached (max-out).
+type ActuatedStrategy struct {
+	phases          []*ActuatedPhase
+	nextPhaseIndex  int
+	activePhase     *ActuatedPhase
+	activeLanes     [][2]string       // Lanes of the active phase that turned GREEN
+	phaseStartTick  int               // Tick the active phase turned GREEN
+	lastTrafficTick int               // Last tick traffic was seen on the active phase
+	arrived         map[[2]string]int // Traffic that arrived on the lanes of the active phase when it was last checked
+	ending          bool              // Whether or not the active phase is ORANGE
+}
+
+func NewActuatedStrategy(phases []*ActuatedPhase) *ActuatedStrategy {
+	return &ActuatedStrategy{phases: phases}
+}
+
+func (s *ActuatedStrategy) NextCalls(tc *TrafficController, currentTick int) {
+	s.activePhase = nil
+
+	// Skip the phases without traffic, try every phase once
+	for try := 0; try < len(s.phases); try++ {
+		phase := s.phases[s.nextPhaseIndex]
+		s.nextPhaseIndex = (s.nextPhaseIndex + 1) % len(s.phases)
+		if s.startPhase(tc, phase, currentTick) {
+			return
+		}
+	}
+}
+
+func (s *ActuatedStrategy) startPhase(tc *TrafficController, phase *ActuatedPhase, currentTick int) bool {
+	s.activeLanes = nil
+	s.arrived = map[[2]string]int{}
+	for _, lane := range phase.Lanes {
+		// Only the lanes with traffic get a GREEN light
+		if !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+			continue
+		}
+		if !tc.HasDemand(lane.roadName, lane.laneName) {
+			continue
+		}
+
+		// Stay GREEN until the max green, UpdateCalls ends it sooner
+		tc.StartCall(lane.roadName, lane.laneName, currentTick, phase.MaxGreen, phase.OrangeTime)
+		s.activeLanes = append(s.activeLanes, [2]string{lane.roadName, lane.laneName})
+		s.arrived[[2]string{lane.roadName, lane.laneName}] = tc.GetIntersection().GetArrivedTrafficByLane(lane.roadName, lane.laneName)
+	}
+	if len(s.activeLanes) == 0 {
+		return false
+	}
+
+	// Remember the phase
+	s.activePhase = phase
+	s.phaseStartTick = currentTick
+	s.lastTrafficTick = currentTick
+	s.ending = false
+	return true
+}
+
+func (s *ActuatedStrategy) UpdateCalls(tc *TrafficController, currentTick int) {
+	if s.activePhase == nil || s.ending {
+		return
+	}
+
+	// Traffic that arrives extends the GREEN light, also when it crossed in the same tick.
+	// Traffic that is still waiting extends it too, so the queue gets to cross.
+	for _, lane := range s.activeLanes {
+		arrived := tc.GetIntersection().GetArrivedTrafficByLane(lane[0], lane[1])
+		if arrived > s.arrived[lane] || tc.GetIntersection().GetWaitingTrafficByLane(lane[0], lane[1]) > 0 {
+			s.lastTrafficTick = currentTick
+		}
+		s.arrived[lane] = arrived
+	}
+
+	// Check if the phase should end
+	greenTime := currentTick - s.phaseStartTick
+	if greenTime < s.activePhase.MinGreen {
+		return
+	}
+	if greenTime >= s.activePhase.MaxGreen {
+		// The controller ends the calls on the max green
+		log.Default().Println("TC: Max-out after", greenTime, "ticks")
+		s.ending = true
+		return
+	}
+	if currentTick-s.lastTrafficTick >= s.activePhase.GapTime {
+		log.Default().Println("TC: Gap-out after", greenTime, "ticks")
+		s.ending = true
+		for _, lane := range s.activeLanes {
+			tc.EndCall(lane[0], lane[1], currentTick)
+		}
+	}
+}
+
+func (s *ActuatedStrategy) OnRoadTraffic(tc *TrafficController, roadName string, laneName string) {
+	// Every phase with traffic gets its turn, jumping ahead lets the other phases wait too long.
+	// The arrivals on the active phase are counted in UpdateCalls.
+}
+
+func (s *ActuatedStrategy) OnRoadEmpty(tc *TrafficController, roadName string, laneName string) {
+	// Don't end the GREEN light yet, traffic can still arrive within the gap time
+}
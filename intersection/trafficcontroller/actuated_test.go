@@ -0,0 +1,121 @@
+package trafficcontroller
+
+import "testing"
+
+// runActuated returns the tick the light turned ORANGE
+func runActuated(t *testing.T, waiting int, arrivals func(tick int) int) int {
+	in := newFakeIntersection()
+	in.waiting["NORTH:LEFT"] = waiting
+	phase := &ActuatedPhase{
+		Lanes:      []*TrafficPattern{{roadName: "NORTH", laneName: "LEFT"}},
+		MinGreen:   3,
+		MaxGreen:   10,
+		GapTime:    2,
+		OrangeTime: 1,
+	}
+	tc := &TrafficController{intersection: in, strategy: NewActuatedStrategy([]*ActuatedPhase{phase})}
+
+	for tick := 0; tick < 20; tick++ {
+		// Let the traffic cross and arrive
+		in.currentTick = tick
+		in.tick()
+		in.waiting["NORTH:LEFT"] += arrivals(tick)
+		in.arrived["NORTH:LEFT"] += arrivals(tick)
+
+		// Check when the light changes
+		before := in.states["NORTH:LEFT"]
+		tc.Tick(tick)
+		if before == "GREEN" && in.states["NORTH:LEFT"] == "ORANGE" {
+			return tick
+		}
+		if tick == 0 && in.states["NORTH:LEFT"] != "GREEN" {
+			t.Fatalf(`Light should turn GREEN on the first tick, got %s`, in.states["NORTH:LEFT"])
+		}
+	}
+	t.Fatalf(`Light never turned ORANGE`)
+	return 0
+}
+
+func TestActuatedGapOut(t *testing.T) {
+	// The last traffic is seen on tick 4, then the gap time of 2 ticks
+	orangeTick := runActuated(t, 5, func(tick int) int { return 0 })
+	if orangeTick != 6 {
+		t.Fatalf(`Light should gap-out on tick 6, got %d`, orangeTick)
+	}
+}
+
+func TestActuatedMinGreen(t *testing.T) {
+	// The gap time has passed on tick 3, but the min green is 3 ticks
+	orangeTick := runActuated(t, 1, func(tick int) int { return 0 })
+	if orangeTick != 3 {
+		t.Fatalf(`Light should stay GREEN for the min green, turned ORANGE on tick %d`, orangeTick)
+	}
+}
+
+func TestActuatedMaxGreen(t *testing.T) {
+	// New traffic keeps coming, so the light should stop on the max green
+	orangeTick := runActuated(t, 1, func(tick int) int { return 2 })
+	if orangeTick != 10 {
+		t.Fatalf(`Light should max-out on tick 10, got %d`, orangeTick)
+	}
+}
+
+func TestActuatedExtendsOnArrival(t *testing.T) {
+	// A vehicle arriving on tick 6 extends the GREEN light
+	orangeTick := runActuated(t, 5, func(tick int) int {
+		if tick == 6 {
+			return 1
+		}
+		return 0
+	})
+	if orangeTick != 8 {
+		t.Fatalf(`Light should gap-out on tick 8, got %d`, orangeTick)
+	}
+}
+
+func TestActuatedExtendsOnArrivalWithoutQueue(t *testing.T) {
+	// The vehicles arriving on tick 5 and 7 cross before the traffic controller sees a queue
+	in := newFakeIntersection()
+	in.waiting["NORTH:LEFT"] = 5
+	phase := &ActuatedPhase{Lanes: []*TrafficPattern{{roadName: "NORTH", laneName: "LEFT"}}, MinGreen: 3, MaxGreen: 20, GapTime: 2, OrangeTime: 1}
+	tc := &TrafficController{intersection: in, strategy: NewActuatedStrategy([]*ActuatedPhase{phase})}
+	for tick := 0; tick < 20; tick++ {
+		in.currentTick = tick
+		in.tick()
+		if tick == 5 || tick == 7 {
+			in.arrived["NORTH:LEFT"]++
+		}
+		tc.Tick(tick)
+		if in.states["NORTH:LEFT"] == "ORANGE" {
+			if tick != 9 {
+				t.Fatalf(`Light should gap-out on tick 9, got %d`, tick)
+			}
+			return
+		}
+	}
+	t.Fatalf(`Light never turned ORANGE`)
+}
+
+func TestActuatedTimingFromConfig(t *testing.T) {
+	phases := GetActuatedPhases([]ActuatedTiming{{Phase: 2, MinGreen: 4, GapTime: 3}})
+	if phases[2].MinGreen != 4 || phases[2].GapTime != 3 || phases[2].MaxGreen != ACTUATED_PHASES[2].MaxGreen {
+		t.Fatalf(`The timing of the config should change the phase, got %+v`, phases[2])
+	}
+	if ACTUATED_PHASES[2].MinGreen == 4 {
+		t.Fatalf(`The default phases should not change`)
+	}
+	errs := ValidateActuatedTimings([]ActuatedTiming{{Phase: 11}, {Phase: 0, GapTime: -1}, {Phase: 0}, {Phase: 1, MinGreen: 30}})
+	if len(errs) != 4 {
+		t.Fatalf(`Every wrong timing should be found, got %v`, errs)
+	}
+}
+
+func TestActuatedNotWorseThanPattern(t *testing.T) {
+	// The scenario of go test -bench Scenario
+	pattern := runScenario(NewPatternStrategy(), 7, 5000)
+	actuated := runScenario(NewActuatedStrategy(ACTUATED_PHASES), 7, 5000)
+	if actuated.AverageDelay > pattern.AverageDelay || actuated.P95Delay > pattern.P95Delay || actuated.MaxQueue > pattern.MaxQueue {
+		t.Fatalf(`ACTUATED should not be worse than PATTERN, got a delay of %.1f (p95 %d, max queue %d) against %.1f (p95 %d, max queue %d)`,
+			actuated.AverageDelay, actuated.P95Delay, actuated.MaxQueue, pattern.AverageDelay, pattern.P95Delay, pattern.MaxQueue)
+	}
+}
@@ -0,0 +1,287 @@
+package trafficcontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const DEFAULT_REQUEST_TIMEOUT time.Duration = time.Second
+const DEFAULT_RETRIES int = 2
+const DEFAULT_BACKOFF time.Duration = 100 * time.Millisecond
+
+// ConnectionSettings are the settings of the connection to the API of the intersection, 0 for the default
+type ConnectionSettings struct {
+	BaseUrl string        // Where the API of the intersection runs, like http://localhost:8080
+	Timeout time.Duration // Time a request may take
+	Retries int           // Times a request is tried again when the API can't be reached, 0 for none
+	Backoff time.Duration // Time before the first retry, it doubles every retry
+}
+
+// WithDefaults fills in the settings that are 0, except the retries
+func (c ConnectionSettings) WithDefaults() ConnectionSettings {
+	if c.Timeout <= 0 {
+		c.Timeout = DEFAULT_REQUEST_TIMEOUT
+	}
+	if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DEFAULT_BACKOFF
+	}
+	return c
+}
+
+// IntersectionApiConnection is the IntersectionBridge of a traffic controller in SEPERATED mode, it calls the API.
+// When the API can't be reached, the answers are the safe ones: no traffic, no lane and the lights FLASH,
+// an emergency vehicle is still there. The other requests of that tick fail right away, so the tick doesn't
+// wait for the retries of every request.
+type IntersectionApiConnection struct {
+	settings    ConnectionSettings
+	client      *http.Client
+	currentTick int
+	unreachable error // Why the API couldn't be reached in this tick, nil when it could
+}
+
+// laneReply is a lane in the reply of the API, a lane name can have more than one lane
+type laneReply struct {
+	State        string `json:"state"`
+	Traffic      int    `json:"traffic"`
+	Arrived      int    `json:"arrived"`
+	Called       bool   `json:"called"`
+	CrossingTime int    `json:"crossingTime"`
+	Emergency    bool   `json:"emergency"`
+	Bus          bool   `json:"bus"`
+}
+
+func NewIntersectionApiConnection(settings ConnectionSettings) *IntersectionApiConnection {
+	settings = settings.WithDefaults()
+	return &IntersectionApiConnection{settings: settings, client: &http.Client{Timeout: settings.Timeout}}
+}
+
+// request calls the API and returns the status code and the body of the reply.
+// It tries again with a growing wait when the API can't be reached or failed.
+func (ic *IntersectionApiConnection) request(method string, path string, body any) (int, []byte, error) {
+	// Don't try again in the same tick
+	if ic.unreachable != nil {
+		return 0, nil, ic.unreachable
+	}
+
+	var jsonData []byte
+	if body != nil {
+		jsonData, _ = json.Marshal(body)
+	}
+	backoff := ic.settings.Backoff
+	var err error
+	for attempt := 0; attempt <= ic.settings.Retries; attempt++ {
+		// Wait a little longer before every retry
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var status int
+		var reply []byte
+		status, reply, err = ic.send(method, path, jsonData)
+		if err == nil {
+			return status, reply, nil
+		}
+	}
+	log.Default().Println("TC: Intersection unreachable:", err)
+	ic.unreachable = err
+	return 0, nil, err
+}
+
+func (ic *IntersectionApiConnection) send(method string, path string, jsonData []byte) (int, []byte, error) {
+	request, err := http.NewRequest(method, ic.settings.BaseUrl+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return 0, nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	result, err := ic.client.Do(request)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer result.Body.Close()
+	reply, err := io.ReadAll(result.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if result.StatusCode >= 500 {
+		return 0, nil, fmt.Errorf("%s %s returned %d", method, path, result.StatusCode)
+	}
+	return result.StatusCode, reply, nil
+}
+
+// getLanes returns the lanes with the name, nil when the lane doesn't exist.
+// The error tells the lane doesn't exist apart from an API that can't be reached or gave an invalid reply.
+func (ic *IntersectionApiConnection) getLanes(roadName string, laneName string) ([]laneReply, error) {
+	query := url.Values{"road": {roadName}, "lane": {laneName}}
+	status, reply, err := ic.request(http.MethodGet, "/road/lane?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == 404 {
+		return nil, nil
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("lane %s:%s returned %d", roadName, laneName, status)
+	}
+	var lanes []laneReply
+	if err := json.Unmarshal(reply, &lanes); err != nil {
+		log.Default().Println("TC: Invalid reply for", roadName, ":", laneName, err)
+		return nil, err
+	}
+	return lanes, nil
+}
+
+// Heartbeat tells the watchdog of the intersection the traffic controller is still there and what it does,
+// returns true when the watchdog already lost the traffic controller and the lights FLASH
+func (ic *IntersectionApiConnection) Heartbeat(controller ControllerStatus) (bool, error) {
+	status, reply, err := ic.request(http.MethodPost, "/heartbeat", controller)
+	if err != nil {
+		return false, err
+	}
+	if status != 200 {
+		return false, fmt.Errorf("heartbeat returned %d", status)
+	}
+	var data struct {
+		FailSafe bool `json:"failSafe"`
+	}
+	if err := json.Unmarshal(reply, &data); err != nil {
+		return false, fmt.Errorf("invalid heartbeat reply: %w", err)
+	}
+	return data.FailSafe, nil
+}
+
+func (ic *IntersectionApiConnection) FullStopLights() {
+	status, reply, err := ic.request(http.MethodPost, "/stop", nil)
+	if err == nil && status != 200 {
+		log.Default().Println("TC: Full stop refused:", string(reply))
+	}
+}
+
+func (ic *IntersectionApiConnection) CollisionWarningOnGreen(roadName string, laneName string) bool {
+	query := url.Values{"road": {roadName}, "lane": {laneName}}
+	status, reply, err := ic.request(http.MethodGet, "/road/lane/state?"+query.Encode(), nil)
+	if err != nil || status != 200 {
+		// Without an answer the lane is not safe
+		return true
+	}
+	var data bool
+	if err := json.Unmarshal(reply, &data); err != nil {
+		return true
+	}
+	return data
+}
+
+func (ic *IntersectionApiConnection) HasLane(roadName string, laneName string) bool {
+	lanes, _ := ic.getLanes(roadName, laneName)
+	return len(lanes) > 0
+}
+
+func (ic *IntersectionApiConnection) GetWaitingTrafficByLane(roadName string, laneName string) int {
+	var totalTraffic int
+	lanes, _ := ic.getLanes(roadName, laneName)
+	for _, lane := range lanes {
+		totalTraffic += lane.Traffic
+	}
+	return totalTraffic
+}
+
+func (ic *IntersectionApiConnection) GetArrivedTrafficByLane(roadName string, laneName string) int {
+	var arrivedTraffic int
+	lanes, _ := ic.getLanes(roadName, laneName)
+	for _, lane := range lanes {
+		arrivedTraffic += lane.Arrived
+	}
+	return arrivedTraffic
+}
+
+func (ic *IntersectionApiConnection) SetLightState(roadName string, laneName string, state string) bool {
+	type inputDataType struct {
+		Road  string `json:"road"`
+		Lane  string `json:"lane"`
+		State string `json:"state"`
+	}
+	status, reply, err := ic.request(http.MethodPost, "/road/lane/state", inputDataType{Road: roadName, Lane: laneName, State: state})
+	if err != nil {
+		return false
+	}
+	if status == 200 {
+		return true
+	}
+
+	// Log why the intersection refused the change
+	var reason struct {
+		Reason string `json:"reason"`
+	}
+	json.Unmarshal(reply, &reason)
+	log.Default().Println("TC: Light change refused:", reason.Reason)
+	return false
+}
+
+func (ic *IntersectionApiConnection) GetLaneState(roadName string, laneName string) string {
+	lanes, _ := ic.getLanes(roadName, laneName)
+	if len(lanes) == 0 {
+		return "FLASH"
+	}
+	return lanes[0].State
+}
+
+func (ic *IntersectionApiConnection) IsCalled(roadName string, laneName string) bool {
+	lanes, _ := ic.getLanes(roadName, laneName)
+	for _, lane := range lanes {
+		if lane.Called {
+			return true
+		}
+	}
+	return false
+}
+
+func (ic *IntersectionApiConnection) GetCrossingTime(roadName string, laneName string) int {
+	lanes, _ := ic.getLanes(roadName, laneName)
+	if len(lanes) == 0 {
+		return 0
+	}
+	return lanes[0].CrossingTime
+}
+
+// HasEmergencyVehicle returns true without an answer, so a preemption keeps running until the API answers again
+func (ic *IntersectionApiConnection) HasEmergencyVehicle(roadName string, laneName string) bool {
+	lanes, err := ic.getLanes(roadName, laneName)
+	if err != nil {
+		return true
+	}
+	for _, lane := range lanes {
+		if lane.Emergency {
+			return true
+		}
+	}
+	return false
+}
+
+func (ic *IntersectionApiConnection) HasBus(roadName string, laneName string) bool {
+	lanes, _ := ic.getLanes(roadName, laneName)
+	for _, lane := range lanes {
+		if lane.Bus {
+			return true
+		}
+	}
+	return false
+}
+
+func (ic *IntersectionApiConnection) GetCurrentTick() int {
+	return ic.currentTick
+}
+
+// SetCurrentTick starts a new tick, the API is tried again
+func (ic *IntersectionApiConnection) SetCurrentTick(tick int) {
+	ic.currentTick = tick
+	ic.unreachable = nil
+}
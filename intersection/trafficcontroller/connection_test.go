@@ -0,0 +1,110 @@
+package trafficcontroller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionRetries(t *testing.T) {
+	// The API fails twice before it answers
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte(`[{"state": "GREEN", "traffic": 3}]`))
+	}))
+	defer server.Close()
+
+	ic := NewIntersectionApiConnection(ConnectionSettings{BaseUrl: server.URL, Retries: 2, Backoff: time.Millisecond})
+	if state := ic.GetLaneState("NORTH", "FORWARD"); state != "GREEN" {
+		t.Fatalf(`The state should be GREEN after the retries, got %s`, state)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf(`The API should be called 3 times, got %d`, requests.Load())
+	}
+}
+
+func TestConnectionFailsSafe(t *testing.T) {
+	// The API answers without lanes, or too late
+	var slow atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	ic := NewIntersectionApiConnection(ConnectionSettings{BaseUrl: server.URL, Timeout: 10 * time.Millisecond, Retries: 1, Backoff: time.Millisecond})
+	if state := ic.GetLaneState("NORTH", "FORWARD"); state != "FLASH" {
+		t.Fatalf(`A lane without a reply should FLASH, got %s`, state)
+	}
+	if crossingTime := ic.GetCrossingTime("NORTH", "CROSSWALK"); crossingTime != 0 {
+		t.Fatalf(`A lane without a reply should not have a crossing time, got %d`, crossingTime)
+	}
+	slow.Store(true)
+	if _, err := ic.Heartbeat(ControllerStatus{}); err == nil {
+		t.Fatalf(`A heartbeat that takes too long should fail`)
+	}
+	if !ic.CollisionWarningOnGreen("NORTH", "FORWARD") {
+		t.Fatalf(`A lane should not turn GREEN without a reply`)
+	}
+}
+
+func TestConnectionKeepsEmergencyVehicle(t *testing.T) {
+	// The API answers, doesn't know the lane and then fails
+	var status atomic.Int32
+	status.Store(200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte(`[{"state": "GREEN", "emergency": false}]`))
+	}))
+	defer server.Close()
+
+	ic := NewIntersectionApiConnection(ConnectionSettings{BaseUrl: server.URL, Retries: 1, Backoff: time.Millisecond})
+	if ic.HasEmergencyVehicle("NORTH", "FORWARD") {
+		t.Fatalf(`The lane should not have an emergency vehicle`)
+	}
+	status.Store(404)
+	if ic.HasEmergencyVehicle("NORTH", "CROSSWALK") {
+		t.Fatalf(`A lane that doesn't exist should not have an emergency vehicle`)
+	}
+
+	// Without an answer the emergency vehicle may still be there
+	status.Store(503)
+	if !ic.HasEmergencyVehicle("NORTH", "FORWARD") {
+		t.Fatalf(`The emergency vehicle should still be there when the API fails`)
+	}
+}
+
+func TestConnectionFailsFastInTheSameTick(t *testing.T) {
+	// The API is down
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(503)
+	}))
+	defer server.Close()
+
+	// Only the first request of the tick is tried again
+	ic := NewIntersectionApiConnection(ConnectionSettings{BaseUrl: server.URL, Retries: 2, Backoff: time.Millisecond})
+	ic.SetCurrentTick(1)
+	ic.GetLaneState("NORTH", "FORWARD")
+	ic.GetWaitingTrafficByLane("NORTH", "FORWARD")
+	if requests.Load() != 3 {
+		t.Fatalf(`The API should be called 3 times in one tick, got %d`, requests.Load())
+	}
+
+	// The next tick tries again, without retries when they are off
+	ic.settings.Retries = 0
+	ic.SetCurrentTick(2)
+	ic.GetLaneState("NORTH", "FORWARD")
+	if requests.Load() != 4 {
+		t.Fatalf(`The API should be called once more in the next tick, got %d`, requests.Load())
+	}
+}
@@ -0,0 +1,144 @@
+package trafficcontroller
+
+import (
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+const DEFAULT_CYCLE_LENGTH int = 60
+
+// CORRIDOR_PHASE are the lanes of the corridor, traffic drives from WEST to EAST and back
+var CORRIDOR_PHASE = []*TrafficPattern{
+	{roadName: "WEST", laneName: "FORWARD"}, {roadName: "WEST", laneName: "RIGHT"},
+	{roadName: "EAST", laneName: "FORWARD"}, {roadName: "EAST", laneName: "RIGHT"},
+}
+
+// CoordinationSettings are the settings of the green wave in the config, 0 for the default
+type CoordinationSettings struct {
+	Enabled       bool `json:"enabled"`       // Whether or not the intersections of the corridor form a green wave
+	CycleLength   int  `json:"cycleLength"`   // Ticks of one cycle, the same for every intersection
+	CorridorGreen int  `json:"corridorGreen"` // Ticks the corridor is GREEN in every cycle, half the cycle by default
+}
+
+// WithDefaults fills in the settings that are 0
+func (c CoordinationSettings) WithDefaults() CoordinationSettings {
+	if c.CycleLength <= 0 {
+		c.CycleLength = DEFAULT_CYCLE_LENGTH
+	}
+	if c.CorridorGreen <= 0 {
+		c.CorridorGreen = c.CycleLength / 2
+	}
+	return c
+}
+
+// CoordinatedStrategy runs a fixed cycle, the corridor turns GREEN at the offset of the intersection in every cycle.
+// The offsets of the intersections along the corridor follow the travel time, so a vehicle that drives the
+// corridor gets GREEN at every intersection. The other lanes share the rest of the cycle.
+type CoordinatedStrategy struct {
+	cycleLength    int
+	offset         int
+	corridorGreen  int
+	nextPhaseIndex int
+	phases         [][]*TrafficPattern
+}
+
+func NewCoordinatedStrategy(cycleLength int, offset int, corridorGreen int) *CoordinatedStrategy {
+	// The other lanes use the phases of the pattern that have lanes outside the corridor
+	var phases [][]*TrafficPattern
+	for _, phase := range TRAFFIC_PATTERN {
+		if !isCorridorPhase(phase) {
+			phases = append(phases, phase)
+		}
+	}
+	return &CoordinatedStrategy{cycleLength: cycleLength, offset: offset, corridorGreen: corridorGreen, phases: phases}
+}
+
+// GetCyclePosition returns the tick within the cycle, the corridor turns GREEN at 0
+func (s *CoordinatedStrategy) GetCyclePosition(currentTick int) int {
+	return ((currentTick-s.offset)%s.cycleLength + s.cycleLength) % s.cycleLength
+}
+
+func (s *CoordinatedStrategy) NextCalls(tc *TrafficController, currentTick int) {
+	position := s.GetCyclePosition(currentTick)
+
+	// Turn the corridor GREEN until the end of its window, also without traffic, the next vehicles are on their way
+	if position < s.corridorGreen {
+		started := false
+		for _, lane := range CORRIDOR_PHASE {
+			if !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+				continue
+			}
+			tc.StartCall(lane.roadName, lane.laneName, currentTick, s.corridorGreen-position, RED_WAIT_TIME-ORANGE_WAIT_TIME)
+			started = true
+		}
+		if started {
+			log.Default().Println("TC: Corridor GREEN at tick", position, "of the cycle")
+			return
+		}
+	}
+
+	// Give the other lanes GREEN when they are RED again before the next corridor GREEN, try every phase once
+	for try := 0; try < len(s.phases); try++ {
+		phase := s.phases[s.nextPhaseIndex]
+		s.nextPhaseIndex = (s.nextPhaseIndex + 1) % len(s.phases)
+		tc.GetCounters().AddPatternStep()
+
+		hasNewCurrentCalls := false
+		for _, lane := range phase {
+			if !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) || !tc.HasDemand(lane.roadName, lane.laneName) {
+				continue
+			}
+			greenTime := s.getGreenTime(tc, lane, position)
+			if greenTime <= 0 {
+				continue
+			}
+			tc.StartCall(lane.roadName, lane.laneName, currentTick, greenTime, RED_WAIT_TIME-ORANGE_WAIT_TIME)
+			hasNewCurrentCalls = true
+		}
+		if hasNewCurrentCalls {
+			return
+		}
+	}
+}
+
+// getGreenTime returns the GREEN of a lane that fits before the next corridor GREEN, at most the GREEN of the pattern
+func (s *CoordinatedStrategy) getGreenTime(tc *TrafficController, lane *TrafficPattern, position int) int {
+	timing := tc.GetTiming()
+	orangeTime := max(RED_WAIT_TIME-ORANGE_WAIT_TIME, timing.GetMinOrange())
+	if road.IsCrossingLane(lane.laneName) {
+		orangeTime = max(orangeTime, tc.GetIntersection().GetCrossingTime(lane.roadName, lane.laneName))
+	}
+	return min(ORANGE_WAIT_TIME, s.cycleLength-position-timing.RedOrange-orangeTime-timing.AllRed)
+}
+
+func (s *CoordinatedStrategy) OnRoadTraffic(tc *TrafficController, roadName string, laneName string) {
+	// The cycle is fixed, the lane waits for its turn
+}
+
+func (s *CoordinatedStrategy) OnRoadEmpty(tc *TrafficController, roadName string, laneName string) {
+	// The corridor stays GREEN for the next vehicles, the other lanes give their time back
+	if isCorridorLane(roadName, laneName) {
+		return
+	}
+	tc.EndCall(roadName, laneName, tc.GetIntersection().GetCurrentTick())
+}
+
+func isCorridorLane(roadName string, laneName string) bool {
+	for _, lane := range CORRIDOR_PHASE {
+		if lane.roadName == roadName && lane.laneName == laneName {
+			return true
+		}
+	}
+	return false
+}
+
+// isCorridorPhase returns true when all lanes of the phase are lanes of the corridor
+func isCorridorPhase(phase []*TrafficPattern) bool {
+	for _, lane := range phase {
+		if !isCorridorLane(lane.roadName, lane.laneName) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package trafficcontroller
+
+import "testing"
+
+func TestCoordinatedStrategyFollowsTheOffset(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["WEST:FORWARD"] = 0
+	in.waiting["NORTH:ALL"] = 50
+	tc := &TrafficController{intersection: in, strategy: NewCoordinatedStrategy(30, 5, 10)}
+
+	var west, north []string
+	for tick := 0; tick < 17; tick++ {
+		in.currentTick = tick
+		in.tick()
+		tc.Tick(tick)
+		west = append(west, in.states["WEST:FORWARD"])
+		north = append(north, in.states["NORTH:ALL"])
+	}
+
+	// NORTH only gets the GREEN that fits before the corridor turns GREEN on the offset
+	if north[1] != "GREEN" || north[3] != "GREEN" || north[4] != "ORANGE" || north[5] != "RED" {
+		t.Fatalf(`NORTH should be RED before the offset, got %v`, north)
+	}
+
+	// The corridor is GREEN for the corridor green, also without traffic
+	if west[4] == "GREEN" || west[5] != "GREEN" || west[14] != "GREEN" || west[15] != "ORANGE" {
+		t.Fatalf(`WEST should be GREEN from the offset for 10 ticks, got %v`, west)
+	}
+	if north[16] != "GREEN" {
+		t.Fatalf(`NORTH should go after the corridor, got %v`, north)
+	}
+}
+
+func TestCoordinatedStrategySkipsTheCorridorPhase(t *testing.T) {
+	s := NewCoordinatedStrategy(30, 0, 10)
+	if len(s.phases) != len(TRAFFIC_PATTERN)-1 {
+		t.Fatalf(`Only the phase of the corridor should be skipped, got %d of %d phases`, len(s.phases), len(TRAFFIC_PATTERN))
+	}
+	for _, phase := range s.phases {
+		if isCorridorPhase(phase) {
+			t.Fatalf(`The phase of the corridor should be skipped`)
+		}
+	}
+}
+
+func TestCountersPerController(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:ALL"] = 50
+	first := &TrafficController{intersection: in, strategy: NewCoordinatedStrategy(30, 5, 10)}
+	second := &TrafficController{intersection: newFakeIntersection(), strategy: NewCoordinatedStrategy(30, 5, 10)}
+	for tick := 0; tick < 5; tick++ {
+		in.currentTick = tick
+		in.tick()
+		first.Tick(tick)
+	}
+	if first.GetStatus().Counters.PatternSteps == 0 || second.GetStatus().Counters.PatternSteps != 0 {
+		t.Fatalf(`Only the first traffic controller should count its steps, got %d and %d`, first.GetStatus().Counters.PatternSteps, second.GetStatus().Counters.PatternSteps)
+	}
+}
@@ -0,0 +1,76 @@
+package trafficcontroller
+
+// fakeIntersection only counts the traffic, a GREEN light lets one vehicle cross every tick
+type fakeIntersection struct {
+	currentTick int
+	waiting     map[string]int    // Waiting traffic by ROAD:LANE
+	arrived     map[string]int    // Traffic that arrived by ROAD:LANE
+	states      map[string]string // Light state by ROAD:LANE
+	called      map[string]bool   // Pressed buttons by ROAD:LANE
+	emergency   map[string]bool   // Emergency vehicles by ROAD:LANE, they cross with the first traffic
+	bus         map[string]bool   // Buses by ROAD:LANE, the test decides when they crossed
+}
+
+func newFakeIntersection() *fakeIntersection {
+	return &fakeIntersection{waiting: map[string]int{}, arrived: map[string]int{}, states: map[string]string{}, called: map[string]bool{}, emergency: map[string]bool{}, bus: map[string]bool{}}
+}
+
+func (f *fakeIntersection) tick() {
+	for key, state := range f.states {
+		if state == "GREEN" && f.waiting[key] > 0 {
+			f.waiting[key]--
+			f.emergency[key] = false
+		}
+	}
+}
+
+func (f *fakeIntersection) GetCurrentTick() int     { return f.currentTick }
+func (f *fakeIntersection) SetCurrentTick(tick int) { f.currentTick = tick }
+
+func (f *fakeIntersection) FullStopLights() {
+	for key := range f.states {
+		f.states[key] = "RED"
+	}
+}
+
+func (f *fakeIntersection) CollisionWarningOnGreen(roadName string, laneName string) bool {
+	return false
+}
+
+func (f *fakeIntersection) HasLane(roadName string, laneName string) bool {
+	_, ok := f.waiting[roadName+":"+laneName]
+	return ok
+}
+
+func (f *fakeIntersection) GetWaitingTrafficByLane(roadName string, laneName string) int {
+	return f.waiting[roadName+":"+laneName]
+}
+
+func (f *fakeIntersection) GetArrivedTrafficByLane(roadName string, laneName string) int {
+	return f.arrived[roadName+":"+laneName]
+}
+
+func (f *fakeIntersection) SetLightState(roadName string, laneName string, state string) bool {
+	f.states[roadName+":"+laneName] = state
+	return true
+}
+
+func (f *fakeIntersection) GetLaneState(roadName string, laneName string) string {
+	return f.states[roadName+":"+laneName]
+}
+
+func (f *fakeIntersection) IsCalled(roadName string, laneName string) bool {
+	return f.called[roadName+":"+laneName]
+}
+
+func (f *fakeIntersection) GetCrossingTime(roadName string, laneName string) int {
+	return 15
+}
+
+func (f *fakeIntersection) HasEmergencyVehicle(roadName string, laneName string) bool {
+	return f.emergency[roadName+":"+laneName]
+}
+
+func (f *fakeIntersection) HasBus(roadName string, laneName string) bool {
+	return f.bus[roadName+":"+laneName]
+}
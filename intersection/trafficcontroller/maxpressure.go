@@ -0,0 +1,168 @@
+package trafficcontroller
+
+import (
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+const DEFAULT_DECISION_INTERVAL int = 2
+const DEFAULT_MIN_PHASE_TIME int = 3
+
+// A phase never stays GREEN longer than this, so the other phases get a turn
+const MAX_PRESSURE_MAX_GREEN int = 60
+const MAX_PRESSURE_ORANGE_TIME int = 1
+
+// MaxPressureStrategy gives the GREEN light to the phase with the highest pressure.
+// The pressure of a lane is the traffic waiting in the lane minus the traffic waiting
+// on the OUTPUT lane it drives to, so traffic is not sent into a full road.
+type MaxPressureStrategy struct {
+	phases           [][]*TrafficPattern // Phases without conflicts, the lanes of a phase turn GREEN together
+	decisionInterval int                 // Ticks between two decisions
+	minPhaseTime     int                 // Ticks a phase stays GREEN at least
+	activePhase      []*TrafficPattern
+	activeLanes      [][2]string // Lanes of the active phase that turned GREEN
+	phaseStartTick   int
+	ending           bool // Whether or not the active phase is ORANGE
+}
+
+func NewMaxPressureStrategy(settings StrategySettings) *MaxPressureStrategy {
+	s := &MaxPressureStrategy{
+		phases:           TRAFFIC_PATTERN,
+		decisionInterval: settings.DecisionInterval,
+		minPhaseTime:     settings.MinPhaseTime,
+	}
+	if s.decisionInterval <= 0 {
+		s.decisionInterval = DEFAULT_DECISION_INTERVAL
+	}
+	if s.minPhaseTime <= 0 {
+		s.minPhaseTime = DEFAULT_MIN_PHASE_TIME
+	}
+	return s
+}
+
+func (s *MaxPressureStrategy) NextCalls(tc *TrafficController, currentTick int) {
+	s.activePhase = nil
+
+	// Find the phase with the highest pressure, without pressure the buttons that were pressed get their turn
+	phase, pressure := s.getBestPhase(tc)
+	if phase == nil || pressure <= 0 {
+		phase = s.getCalledPhase(tc)
+		if phase == nil {
+			return
+		}
+	}
+
+	// Turn on every lane of the phase, the UpdateCalls ends it
+	s.activeLanes = nil
+	for _, lane := range phase {
+		if !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+			continue
+		}
+		tc.StartCall(lane.roadName, lane.laneName, currentTick, MAX_PRESSURE_MAX_GREEN, MAX_PRESSURE_ORANGE_TIME)
+		s.activeLanes = append(s.activeLanes, [2]string{lane.roadName, lane.laneName})
+	}
+	s.activePhase = phase
+	s.phaseStartTick = currentTick
+	s.ending = false
+	log.Default().Println("TC: Max pressure phase with pressure", pressure)
+}
+
+func (s *MaxPressureStrategy) UpdateCalls(tc *TrafficController, currentTick int) {
+	if s.activePhase == nil || s.ending {
+		return
+	}
+
+	// Only decide after the min phase time, and then every decision interval
+	greenTime := currentTick - s.phaseStartTick
+	if greenTime < s.minPhaseTime || (greenTime-s.minPhaseTime)%s.decisionInterval != 0 {
+		return
+	}
+
+	// Keep the phase when it still has the highest pressure
+	bestPhase, bestPressure := s.getBestPhase(tc)
+	activePressure := s.getPressure(tc, s.activePhase)
+	if bestPhase == nil || activePressure >= bestPressure {
+		return
+	}
+
+	// Switch to the other phase, NextCalls picks it when the lights are RED
+	log.Default().Println("TC: Max pressure switch after", greenTime, "ticks, pressure", activePressure, "<", bestPressure)
+	s.ending = true
+	for _, lane := range s.activeLanes {
+		tc.EndCall(lane[0], lane[1], currentTick)
+	}
+}
+
+func (s *MaxPressureStrategy) OnRoadTraffic(tc *TrafficController, roadName string, laneName string) {
+	// The pressure already counts the waiting traffic
+}
+
+func (s *MaxPressureStrategy) OnRoadEmpty(tc *TrafficController, roadName string, laneName string) {
+	// An empty lane lowers the pressure, the next decision switches the phase
+}
+
+func (s *MaxPressureStrategy) getBestPhase(tc *TrafficController) ([]*TrafficPattern, float64) {
+	var bestPhase []*TrafficPattern
+	var bestPressure float64
+	for _, phase := range s.phases {
+		// Skip the phases without any of our lanes
+		if !s.hasLanes(tc, phase) {
+			continue
+		}
+		pressure := s.getPressure(tc, phase)
+		if bestPhase == nil || pressure > bestPressure {
+			bestPhase = phase
+			bestPressure = pressure
+		}
+	}
+	return bestPhase, bestPressure
+}
+
+// getCalledPhase finds the first phase with a crosswalk or bicycle lane that was called, the button has no pressure
+func (s *MaxPressureStrategy) getCalledPhase(tc *TrafficController) []*TrafficPattern {
+	for _, phase := range s.phases {
+		for _, lane := range phase {
+			if !road.IsCrossingLane(lane.laneName) || !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+				continue
+			}
+			if tc.HasDemand(lane.roadName, lane.laneName) {
+				return phase
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MaxPressureStrategy) getPressure(tc *TrafficController, phase []*TrafficPattern) float64 {
+	var pressure float64
+	for _, lane := range phase {
+		if !tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+			continue
+		}
+
+		// Traffic waiting before the intersection
+		upstream := float64(tc.GetIntersection().GetWaitingTrafficByLane(lane.roadName, lane.laneName))
+
+		// Traffic waiting after the intersection, the average when the lane has more destinations
+		var downstream float64
+		destinations := road.GetDestinations(lane.roadName, lane.laneName)
+		for _, destination := range destinations {
+			downstream += float64(tc.GetIntersection().GetWaitingTrafficByLane(destination, "OUTPUT"))
+		}
+		if len(destinations) > 0 {
+			downstream /= float64(len(destinations))
+		}
+		pressure += upstream - downstream
+	}
+	return pressure
+}
+
+func (s *MaxPressureStrategy) hasLanes(tc *TrafficController, phase []*TrafficPattern) bool {
+	for _, lane := range phase {
+		if tc.GetIntersection().HasLane(lane.roadName, lane.laneName) {
+			return true
+		}
+	}
+	return false
+}
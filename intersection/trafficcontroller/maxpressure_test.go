@@ -0,0 +1,123 @@
+package trafficcontroller
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+func TestMaxPressurePicksHighestPressure(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:LEFT"] = 8
+	in.waiting["SOUTH:FORWARD"] = 6
+	in.waiting["EAST:OUTPUT"] = 5  // NORTH:LEFT drives to the EAST
+	in.waiting["NORTH:OUTPUT"] = 0 // SOUTH:FORWARD drives to the NORTH
+	s := &MaxPressureStrategy{
+		phases: [][]*TrafficPattern{
+			{{roadName: "NORTH", laneName: "LEFT"}},
+			{{roadName: "SOUTH", laneName: "FORWARD"}},
+		},
+		decisionInterval: 2,
+		minPhaseTime:     3,
+	}
+	tc := &TrafficController{intersection: in, strategy: s}
+
+	// SOUTH:FORWARD has a pressure of 6, NORTH:LEFT only 8 - 5 = 3
+	tc.Tick(0)
+	if in.states["SOUTH:FORWARD"] != "GREEN" || in.states["NORTH:LEFT"] == "GREEN" {
+		t.Fatalf(`SOUTH:FORWARD should be GREEN, got %v`, in.states)
+	}
+
+	// The phase stays GREEN for the min phase time, even when the pressure drops
+	in.waiting["SOUTH:FORWARD"] = 0
+	for tick := 1; tick < 3; tick++ {
+		tc.Tick(tick)
+		if in.states["SOUTH:FORWARD"] != "GREEN" {
+			t.Fatalf(`SOUTH:FORWARD should stay GREEN on tick %d, got %s`, tick, in.states["SOUTH:FORWARD"])
+		}
+	}
+
+	// Switch on the first decision after the min phase time
+	tc.Tick(3)
+	if in.states["SOUTH:FORWARD"] != "ORANGE" {
+		t.Fatalf(`SOUTH:FORWARD should turn ORANGE on tick 3, got %s`, in.states["SOUTH:FORWARD"])
+	}
+	tc.Tick(4)
+	if in.states["SOUTH:FORWARD"] != "RED" || in.states["NORTH:LEFT"] != "GREEN" {
+		t.Fatalf(`NORTH:LEFT should be GREEN on tick 4, got %v`, in.states)
+	}
+}
+
+func TestMaxPressureServesPressedButton(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:LEFT"] = 0
+	in.waiting["NORTH:CROSSWALK"] = 0
+	in.called["NORTH:CROSSWALK"] = true
+	tc := &TrafficController{intersection: in, strategy: NewMaxPressureStrategy(StrategySettings{})}
+
+	// Nobody waits, but the button of the crosswalk was pressed
+	tc.Tick(0)
+	if in.states["NORTH:CROSSWALK"] != "GREEN" || in.states["NORTH:LEFT"] == "GREEN" {
+		t.Fatalf(`NORTH:CROSSWALK should be GREEN, got %v`, in.states)
+	}
+}
+
+// runScenario runs the default intersection with a strategy, the seed makes the traffic the same for every strategy
+func runScenario(strategy Strategy, seed int64, ticks int) metrics.Summary {
+	return runBusScenario(strategy, seed, ticks, 0, TransitPrioritySettings{})
+}
+
+// runBusScenario runs the default intersection with a bus every busSpeed ticks on every road
+func runBusScenario(strategy Strategy, seed int64, ticks int, busSpeed uint16, transit TransitPrioritySettings) metrics.Summary {
+	// Run without the logs
+	output := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(output)
+
+	// Create the intersection of intersection.json
+	random := rand.New(rand.NewSource(seed))
+	roads := []*road.Road{
+		road.NewRoad(road.NewRoadInput{Name: "NORTH", Left: 1, Forward: 2, Right: 1, BusSpeed: busSpeed, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "SOUTH", All: 2, BusSpeed: busSpeed, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "EAST", Left: 1, All: 2, BusSpeed: busSpeed, Random: random}),
+		road.NewRoad(road.NewRoadInput{Name: "WEST", Left: 1, Forward: 2, Right: 1, BusSpeed: busSpeed, Random: random}),
+	}
+	in := intersection.NewIntersection(roads)
+	tc := NewTrafficController(in, strategy, road.SignalTiming{})
+	tc.SetTransitPriority(transit)
+
+	// Run the simulation
+	for tick := 0; tick < ticks; tick++ {
+		in.Tick(tick)
+		tc.Tick(tick)
+	}
+	return in.GetMetrics()
+}
+
+func benchmarkScenario(b *testing.B, newStrategy func() Strategy) {
+	var summary metrics.Summary
+	for i := 0; i < b.N; i++ {
+		summary = runScenario(newStrategy(), 7, 5000)
+	}
+	b.ReportMetric(summary.AverageDelay, "avg-delay-ticks")
+	b.ReportMetric(float64(summary.P95Delay), "p95-delay-ticks")
+	b.ReportMetric(float64(summary.MaxQueue), "max-queue")
+}
+
+// Compare the strategies with: go test -bench Scenario ./trafficcontroller
+func BenchmarkPatternScenario(b *testing.B) {
+	benchmarkScenario(b, func() Strategy { return NewPatternStrategy() })
+}
+
+func BenchmarkMaxPressureScenario(b *testing.B) {
+	benchmarkScenario(b, func() Strategy { return NewMaxPressureStrategy(StrategySettings{}) })
+}
+
+func BenchmarkActuatedScenario(b *testing.B) {
+	benchmarkScenario(b, func() Strategy { return NewActuatedStrategy(ACTUATED_PHASES) })
+}
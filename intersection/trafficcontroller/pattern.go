@@ -0,0 +1,119 @@
+package trafficcontroller
+
+import "log"
+
+type TrafficPattern struct {
+	roadName string
+	laneName string
+}
+
+var TRAFFIC_PATTERN [][]*TrafficPattern = [][]*TrafficPattern{
+	{&TrafficPattern{roadName: "NORTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "SOUTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "NORTH", laneName: "BICYCLE"}, &TrafficPattern{roadName: "SOUTH", laneName: "BICYCLE"}, &TrafficPattern{roadName: "EAST", laneName: "CROSSWALK"}, &TrafficPattern{roadName: "WEST", laneName: "CROSSWALK"}},
+	{&TrafficPattern{roadName: "NORTH", laneName: "RIGHT"}, &TrafficPattern{roadName: "WEST", laneName: "RIGHT"}, &TrafficPattern{roadName: "WEST", laneName: "LEFT"}},
+	{&TrafficPattern{roadName: "SOUTH", laneName: "ALL"}},
+	{&TrafficPattern{roadName: "NORTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "NORTH", laneName: "RIGHT"}, &TrafficPattern{roadName: "SOUTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "SOUTH", laneName: "RIGHT"}},
+	{&TrafficPattern{roadName: "EAST", laneName: "ALL"}},
+	{&TrafficPattern{roadName: "WEST", laneName: "FORWARD"}, &TrafficPattern{roadName: "WEST", laneName: "RIGHT"}, &TrafficPattern{roadName: "EAST", laneName: "FORWARD"}, &TrafficPattern{roadName: "EAST", laneName: "RIGHT"}},
+	{&TrafficPattern{roadName: "NORTH", laneName: "ALL"}},
+	{&TrafficPattern{roadName: "NORTH", laneName: "LEFT"}, &TrafficPattern{roadName: "SOUTH", laneName: "LEFT"}},
+	{&TrafficPattern{roadName: "WEST", laneName: "ALL"}},
+	{&TrafficPattern{roadName: "WEST", laneName: "LEFT"}, &TrafficPattern{roadName: "EAST", laneName: "LEFT"}},
+	{&TrafficPattern{roadName: "WEST", laneName: "FORWARD"}, &TrafficPattern{roadName: "EAST", laneName: "FORWARD"}, &TrafficPattern{roadName: "EAST", laneName: "BICYCLE"}, &TrafficPattern{roadName: "WEST", laneName: "BICYCLE"}, &TrafficPattern{roadName: "NORTH", laneName: "CROSSWALK"}, &TrafficPattern{roadName: "SOUTH", laneName: "CROSSWALK"}},
+}
+
+// PatternStrategy walks through TRAFFIC_PATTERN, lanes with a lot of traffic go first
+type PatternStrategy struct {
+	currentPatternIndex int
+	pendingCalls        [][2]string
+}
+
+func NewPatternStrategy() *PatternStrategy {
+	return &PatternStrategy{}
+}
+
+func (s *PatternStrategy) NextCalls(tc *TrafficController, currentTick int) {
+	// Handle pending calls first
+	if len(s.pendingCalls) > 0 {
+		// Process the first call
+		removedItems := 0
+		for index, call := range s.pendingCalls {
+			// Get data
+			roadName := call[0]
+			laneName := call[1]
+
+			// Check for collison warning
+			if tc.GetIntersection().CollisionWarningOnGreen(roadName, laneName) {
+				tc.GetCounters().AddCollisionWarning()
+				continue
+			}
+
+			// Remove the pending call from the list
+			s.pendingCalls = append(s.pendingCalls[:(index-removedItems)], s.pendingCalls[(index-removedItems)+1:]...)
+			removedItems++
+			tc.GetCounters().AddPendingCallOverride()
+
+			// Turn on the lights
+			tc.StartCall(roadName, laneName, currentTick, ORANGE_WAIT_TIME, RED_WAIT_TIME-ORANGE_WAIT_TIME)
+		}
+		return
+	}
+
+	// Continue in the patterns, try every pattern once
+	for try := 0; try <= len(TRAFFIC_PATTERN); try++ {
+		// Go back to the first pattern
+		if s.currentPatternIndex >= len(TRAFFIC_PATTERN) {
+			s.currentPatternIndex = 0
+		}
+
+		// Get the pattern
+		pattern := TRAFFIC_PATTERN[s.currentPatternIndex]
+
+		// Make sure we go to the next pattern
+		s.currentPatternIndex++
+		tc.GetCounters().AddPatternStep()
+
+		// Remember if we enabled any lights
+		hasNewCurrentCalls := false
+
+		// Execute the pattern
+		for _, call := range pattern {
+			// Check if we have the road
+			if !tc.GetIntersection().HasLane(call.roadName, call.laneName) {
+				continue
+			}
+			if !tc.HasDemand(call.roadName, call.laneName) {
+				continue
+			}
+
+			// Turn on the lights
+			tc.StartCall(call.roadName, call.laneName, currentTick, ORANGE_WAIT_TIME, RED_WAIT_TIME-ORANGE_WAIT_TIME)
+			hasNewCurrentCalls = true
+		}
+
+		// Stop when we have new calls, otherwise try the next pattern
+		if hasNewCurrentCalls {
+			return
+		}
+	}
+}
+
+func (s *PatternStrategy) OnRoadTraffic(tc *TrafficController, roadName string, laneName string) {
+	// Check if we already planned the call
+	if tc.IsCurrentCall(roadName, laneName) {
+		return
+	}
+	for _, call := range s.pendingCalls {
+		if call[0] == roadName && call[1] == laneName {
+			return
+		}
+	}
+
+	// Plan the green call
+	s.pendingCalls = append(s.pendingCalls, [2]string{roadName, laneName})
+	log.Default().Println("TC: Planned call for", roadName, ":", laneName)
+}
+
+func (s *PatternStrategy) OnRoadEmpty(tc *TrafficController, roadName string, laneName string) {
+	// Set the light to RED on next tick
+	tc.EndCall(roadName, laneName, tc.GetIntersection().GetCurrentTick())
+}
@@ -0,0 +1,54 @@
+package trafficcontroller
+
+import (
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+)
+
+func TestTrafficPatternHasNoConflicts(t *testing.T) {
+	for index, pattern := range TRAFFIC_PATTERN {
+		// Turn the lanes GREEN one by one, none of them should give a collision warning
+		lights := patternLights{}
+		for _, lane := range pattern {
+			if collisonwarning.CollisionWarningOnGreen(lights, lane.roadName, lane.laneName) {
+				t.Fatalf(`Pattern %d should have no conflicts, %s:%s conflicts with %v`, index, lane.roadName, lane.laneName, lights)
+			}
+			lights[collisonwarning.Movement{Road: lane.roadName, Lane: lane.laneName}] = "GREEN"
+		}
+	}
+}
+
+// patternLights has the light state of every lane that is GREEN
+type patternLights map[collisonwarning.Movement]string
+
+func (p patternLights) HasLane(roadName string, direction string) bool {
+	return true
+}
+
+func (p patternLights) GetLaneState(roadName string, direction string) string {
+	state, ok := p[collisonwarning.Movement{Road: roadName, Lane: direction}]
+	if !ok {
+		return "RED"
+	}
+	return state
+}
+
+func TestPatternServesPressedButton(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:CROSSWALK"] = 0
+	in.called["NORTH:CROSSWALK"] = true
+	tc := &TrafficController{intersection: in, strategy: NewPatternStrategy()}
+	tc.Tick(0)
+
+	// Nobody is waiting, but the button was pressed
+	if in.states["NORTH:CROSSWALK"] != "GREEN" {
+		t.Fatalf(`Crosswalk should turn GREEN after pressing the button, got %s`, in.states["NORTH:CROSSWALK"])
+	}
+
+	// Humans get the crossing time to get to the other side
+	call := tc.currentCalls[0]
+	if call.redTick-call.orangeTick != 15 {
+		t.Fatalf(`Crosswalk should stay ORANGE for the crossing time of 15 ticks, got %d`, call.redTick-call.orangeTick)
+	}
+}
@@ -0,0 +1,155 @@
+package trafficcontroller
+
+import (
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+)
+
+// Preemption gives the lane of an emergency vehicle GREEN until the vehicle crossed.
+// The lanes that cross it turn ORANGE and stay RED for the all red time first,
+// the strategy takes over again when all lights are RED after the vehicle crossed.
+type Preemption struct {
+	roadName  string
+	laneName  string
+	startTick int
+	clearTick int          // Tick the lanes that cross the emergency lane are RED for the all red time
+	call      *CurrentCall // Call of the emergency lane, nil until it gets the light
+	done      bool         // Whether or not the emergency vehicle crossed
+	delay     int          // Ticks the traffic on the other lanes waited during the preemption
+}
+
+// Preempt plans a preemption for an emergency vehicle, emergency vehicles are handled in the order they arrived
+func (t *TrafficController) Preempt(roadName string, laneName string, currentTick int) {
+	for _, p := range t.preemptions {
+		if p.roadName == roadName && p.laneName == laneName {
+			return
+		}
+	}
+	log.Default().Println("TC: Preemption requested for", roadName, ":", laneName, "on tick", currentTick)
+	t.preemptions = append(t.preemptions, &Preemption{roadName: roadName, laneName: laneName, startTick: currentTick})
+}
+
+func (t *TrafficController) IsPreempted() bool {
+	return len(t.preemptions) > 0
+}
+
+func (t *TrafficController) OnEmergency(e events.EmergencyVehicleArrived) {
+	t.Preempt(e.Road, e.Lane, t.intersection.GetCurrentTick())
+}
+
+func (t *TrafficController) tickPreemption(currentTick int) {
+	p := t.preemptions[0]
+
+	// Resume normal operation when all lights are RED again
+	if p.done {
+		if len(t.currentCalls) == 0 {
+			t.preemptions = t.preemptions[1:]
+			log.Default().Println("TC: Preemption for", p.roadName, ":", p.laneName, "ended, back to normal operation")
+		}
+		return
+	}
+
+	// The traffic on the other lanes waits
+	p.delay += t.getWaitingTrafficOnOtherLanes(p.roadName, p.laneName)
+
+	// Check if the emergency vehicle crossed, it also crosses on FLASH
+	if !t.intersection.HasEmergencyVehicle(p.roadName, p.laneName) {
+		p.done = true
+		t.counters.AddPreemption(p.delay)
+		log.Default().Println("TC: Emergency vehicle crossed", p.roadName, ":", p.laneName, "after", currentTick-p.startTick, "ticks, the other lanes waited", p.delay, "ticks longer")
+
+		// Stop the lanes that kept their light during the preemption, unless the lights didn't change
+		if p.call != nil || p.clearTick > 0 {
+			t.endAllCalls(currentTick)
+		}
+		return
+	}
+
+	// Keep the lane GREEN until the emergency vehicle crossed
+	if p.call != nil {
+		orangeTime := p.call.redTick - p.call.orangeTick
+		p.call.orangeTick = max(p.call.orangeTick, currentTick+1)
+		p.call.redTick = p.call.orangeTick + orangeTime
+		return
+	}
+
+	// Stop the calls that cross the lane of the emergency vehicle
+	emergency := collisonwarning.Movement{Road: p.roadName, Lane: p.laneName}
+	cleared := true
+	for _, call := range t.currentCalls {
+		// Use the call of the emergency lane when it didn't turn ORANGE yet
+		if call.roadName == p.roadName && call.laneName == p.laneName {
+			if currentTick < call.orangeTick {
+				p.call = call
+				continue
+			}
+		} else if !collisonwarning.HasConflict(emergency, collisonwarning.Movement{Road: call.roadName, Lane: call.laneName}) {
+			continue
+		}
+
+		// Lanes that are already RED only need the all red time
+		if call.redTick >= currentTick {
+			cleared = false
+			t.stopCall(call, currentTick)
+		}
+		p.clearTick = max(p.clearTick, call.redTick+t.timing.AllRed)
+	}
+	if p.call != nil {
+		log.Default().Println("TC: Preemption, keeping", p.roadName, ":", p.laneName, "GREEN for the emergency vehicle")
+		return
+	}
+
+	// Wait for the all red time
+	if !cleared || currentTick < p.clearTick || currentTick < t.clearanceTick || t.intersection.GetLaneState(p.roadName, p.laneName) != "RED" {
+		return
+	}
+	if t.intersection.CollisionWarningOnGreen(p.roadName, p.laneName) {
+		return
+	}
+
+	// Give the emergency vehicle GREEN, the green time is extended every tick
+	log.Default().Println("TC: Preemption, turning", p.roadName, ":", p.laneName, "GREEN after", currentTick-p.startTick, "ticks")
+	t.StartCall(p.roadName, p.laneName, currentTick, 1, t.timing.GetMinOrange())
+
+	// Try again on the next tick when the intersection refused the light
+	if t.intersection.GetLaneState(p.roadName, p.laneName) == "RED" {
+		t.currentCalls = t.currentCalls[:len(t.currentCalls)-1]
+		return
+	}
+	p.call = t.currentCalls[len(t.currentCalls)-1]
+}
+
+// stopCall ends a call right away, a lane that is RED-ORANGE goes back to RED on this tick
+func (t *TrafficController) stopCall(call *CurrentCall, currentTick int) {
+	if currentTick < call.greenTick {
+		call.greenTick = currentTick - 1
+		call.orangeTick = currentTick - 1
+		call.redTick = currentTick
+	} else if currentTick < call.orangeTick {
+		t.EndCall(call.roadName, call.laneName, currentTick)
+	}
+}
+
+func (t *TrafficController) endAllCalls(currentTick int) {
+	for _, call := range t.currentCalls {
+		if call.redTick >= currentTick {
+			t.stopCall(call, currentTick)
+		}
+	}
+}
+
+// getWaitingTrafficOnOtherLanes returns the traffic that waits on all lanes except the emergency lane
+func (t *TrafficController) getWaitingTrafficOnOtherLanes(roadName string, laneName string) int {
+	var waiting int
+	for _, otherRoad := range collisonwarning.MATRIX_ROADS {
+		for _, otherLane := range collisonwarning.MATRIX_LANES {
+			if otherRoad == roadName && otherLane == laneName {
+				continue
+			}
+			waiting += t.intersection.GetWaitingTrafficByLane(otherRoad, otherLane)
+		}
+	}
+	return waiting
+}
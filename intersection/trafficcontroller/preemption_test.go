@@ -0,0 +1,53 @@
+package trafficcontroller
+
+import "testing"
+
+func TestPreemption(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:FORWARD"] = 20
+	in.waiting["EAST:FORWARD"] = 5
+	in.states["EAST:FORWARD"] = "RED"
+	phase := &ActuatedPhase{
+		Lanes:      []*TrafficPattern{{roadName: "NORTH", laneName: "FORWARD"}},
+		MinGreen:   3,
+		MaxGreen:   30,
+		GapTime:    2,
+		OrangeTime: 1,
+	}
+	tc := &TrafficController{intersection: in, strategy: NewActuatedStrategy([]*ActuatedPhase{phase})}
+
+	// Remember the states of every tick
+	var north, east []string
+	for tick := 0; tick < 10; tick++ {
+		in.currentTick = tick
+		in.tick()
+
+		// The emergency vehicle arrives on EAST
+		if tick == 2 {
+			in.emergency["EAST:FORWARD"] = true
+			tc.Preempt("EAST", "FORWARD", tick)
+		}
+		tc.Tick(tick)
+		north = append(north, in.states["NORTH:FORWARD"])
+		east = append(east, in.states["EAST:FORWARD"])
+	}
+
+	// NORTH crosses EAST, so it turns ORANGE and RED before EAST turns GREEN
+	if north[2] != "ORANGE" || north[3] != "RED" {
+		t.Fatalf(`NORTH should turn ORANGE and RED for the emergency vehicle, got %v`, north)
+	}
+	if east[3] == "GREEN" || east[4] != "GREEN" {
+		t.Fatalf(`EAST should turn GREEN after NORTH is RED, got %v`, east)
+	}
+
+	// The emergency vehicle crosses on tick 5, then EAST ends and normal operation resumes
+	if east[5] != "ORANGE" || east[6] != "RED" {
+		t.Fatalf(`EAST should end after the emergency vehicle crossed, got %v`, east)
+	}
+	if tc.IsPreempted() {
+		t.Fatalf(`Preemption should end after the emergency vehicle crossed`)
+	}
+	if north[9] != "GREEN" {
+		t.Fatalf(`NORTH should get GREEN again after the preemption, got %v`, north)
+	}
+}
@@ -0,0 +1,63 @@
+package trafficcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategy decides which lanes get a GREEN light
+type Strategy interface {
+	// NextCalls is called when all the lights are RED, it turns lanes GREEN with StartCall
+	NextCalls(tc *TrafficController, currentTick int)
+
+	// OnRoadTraffic is called when a lane without a GREEN light has a lot of traffic waiting
+	OnRoadTraffic(tc *TrafficController, roadName string, laneName string)
+
+	// OnRoadEmpty is called when a lane with a GREEN light has no traffic waiting anymore
+	OnRoadEmpty(tc *TrafficController, roadName string, laneName string)
+}
+
+// CallUpdater is a Strategy that changes the running calls, it is called every tick before the lights change
+type CallUpdater interface {
+	UpdateCalls(tc *TrafficController, currentTick int)
+}
+
+// StrategySettings are the settings of the strategies in the config
+type StrategySettings struct {
+	DecisionInterval int `json:"decisionInterval"` // Ticks between two decisions of MAX_PRESSURE
+	MinPhaseTime     int `json:"minPhaseTime"`     // Ticks a phase of MAX_PRESSURE stays GREEN at least
+
+	ActuatedPhases []ActuatedTiming `json:"actuatedPhases"` // Timing of the phases of ACTUATED, the other phases keep ACTUATED_PHASES
+}
+
+const DEFAULT_STRATEGY = "PATTERN"
+
+// STRATEGIES has the strategies that can be picked in the config
+var STRATEGIES = map[string]func(settings StrategySettings) Strategy{
+	"PATTERN": func(settings StrategySettings) Strategy { return NewPatternStrategy() },
+	"ACTUATED": func(settings StrategySettings) Strategy {
+		return NewActuatedStrategy(GetActuatedPhases(settings.ActuatedPhases))
+	},
+	"MAX_PRESSURE": func(settings StrategySettings) Strategy { return NewMaxPressureStrategy(settings) },
+}
+
+func NewStrategy(name string, settings StrategySettings) (Strategy, error) {
+	if name == "" {
+		name = DEFAULT_STRATEGY
+	}
+	newStrategy, ok := STRATEGIES[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(GetStrategyNames(), ", "))
+	}
+	return newStrategy(settings), nil
+}
+
+func GetStrategyNames() []string {
+	var names []string
+	for name := range STRATEGIES {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,15 +1,14 @@
 package trafficcontroller
 
 import (
-	"bytes"
-	"encoding/json"
 	"log"
-	"net/http"
 	"time"
 
-	"github.com/gookit/event"
 	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
 	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
 )
 
 type CurrentCall struct {
@@ -21,49 +20,104 @@ type CurrentCall struct {
 }
 
 type TrafficController struct {
-	intersection        IntersectionBridge
-	currentPatternIndex int
-	pendingCalls        [][2]string
-	currentCalls        []*CurrentCall
+	intersection  IntersectionBridge
+	strategy      Strategy
+	currentCalls  []*CurrentCall
+	timing        road.SignalTiming // Clearance times of the lights
+	clearanceTick int               // Tick the next calls can start after the all red time
+	preemptions   []*Preemption     // Emergency vehicles that need GREEN, the first one is served
+
+	// Priority for late buses
+	transit         TransitPrioritySettings
+	transitRequests []*TransitRequest
+	transitCall     *CurrentCall // Call of a bus lane that went before the strategy, the strategy waits for it
+
+	// Events of the intersection the traffic controller listens to
+	subscriptions []*events.Subscription
+
+	// Decisions of the traffic controller and its strategy
+	counters metrics.ControllerCounters
+}
+
+// CallStatus is a running call, the API shows the running calls as the phase of the traffic controller
+type CallStatus struct {
+	Road       string `json:"road"`
+	Lane       string `json:"lane"`
+	GreenTick  int    `json:"greenTick"`
+	OrangeTick int    `json:"orangeTick"`
+	RedTick    int    `json:"redTick"`
+}
+
+// ControllerStatus is what the traffic controller is doing, it is sent with the heartbeat in SEPERATED mode
+type ControllerStatus struct {
+	Calls     []CallStatus `json:"calls"`
+	Preempted bool         `json:"preempted"` // Whether or not an emergency vehicle has the lights
+	Transit   bool         `json:"transit"`   // Whether or not a bus lane went before the strategy
+
+	Counters metrics.ControllerCounters `json:"counters"` // Decisions of the traffic controller since the start
 }
 
 const ORANGE_WAIT_TIME int = 10
 const RED_WAIT_TIME int = 11
 
-func NewTrafficController(mode string, in *intersection.Intersection) *TrafficController {
-	// Find the right intersection connection
-	var intersectionConnection IntersectionBridge
-	if mode == "INTEGRATED" {
-		intersectionConnection = &IntersectionDirectConnection{in: in}
-	} else {
-		intersectionConnection = &IntersectionApiConnection{}
-	}
+func NewTrafficController(in *intersection.Intersection, strategy Strategy, timing road.SignalTiming) *TrafficController {
+	return newTrafficController(&IntersectionDirectConnection{in: in}, in.GetEvents(), strategy, timing)
+}
+
+func newTrafficController(intersectionConnection IntersectionBridge, bus *events.Bus, strategy Strategy, timing road.SignalTiming) *TrafficController {
 	// Create the traffic controller
-	t := &TrafficController{intersection: intersectionConnection}
-
-	// Register for events
-	event.On("gointersection-road-traffic", event.ListenerFunc(func(e event.Event) error {
-		log.Default().Println("RS: Lot of traffic on", e.Get("road"), ":", e.Get("lane"))
-		t.OnRoadTraffic(e)
-		return nil
-	}), event.Normal)
-	event.On("gointersection-road-empty", event.ListenerFunc(func(e event.Event) error {
-		log.Default().Println("RS: Empty road at", e.Get("road"), ":", e.Get("lane"))
-		t.OnRoadEmpty(e)
-		return nil
-	}), event.Normal)
+	t := &TrafficController{intersection: intersectionConnection, strategy: strategy, timing: timing}
+
+	// Register for the events of the intersection
+	t.subscriptions = []*events.Subscription{
+		events.Subscribe(bus, func(e events.LaneCongested) {
+			log.Default().Println("RS: Lot of traffic on", e.Road, ":", e.Lane)
+			t.OnRoadTraffic(e)
+		}),
+		events.Subscribe(bus, func(e events.LaneEmptied) {
+			log.Default().Println("RS: Empty road at", e.Road, ":", e.Lane)
+			t.OnRoadEmpty(e)
+		}),
+		events.Subscribe(bus, func(e events.EmergencyVehicleArrived) {
+			log.Default().Println("RS: Emergency vehicle on", e.Road, ":", e.Lane)
+			t.OnEmergency(e)
+		}),
+		events.Subscribe(bus, func(e events.BusArrived) {
+			log.Default().Println("RS: Bus on", e.Road, ":", e.Lane)
+			t.OnBus(e)
+		}),
+	}
 	return t
 }
 
-func (t *TrafficController) Tick(currentTick int, try int) {
-	// Stop the loop if we try more than the number of tries
-	if try > len(TRAFFIC_PATTERN) {
-		return
+// Close stops listening to the events of the intersection, the traffic controller no longer changes the lights
+func (t *TrafficController) Close() {
+	for _, subscription := range t.subscriptions {
+		subscription.Unsubscribe()
 	}
+	t.subscriptions = nil
+}
 
+func (t *TrafficController) Tick(currentTick int) {
 	// Take control of the situation
 	if currentTick == 0 {
-		t.intersection.FullStopLights()
+		t.takeControl(currentTick)
+	}
+
+	// Emergency vehicles go first, the strategy waits
+	if t.IsPreempted() {
+		t.tickPreemption(currentTick)
+	}
+	preempted := t.IsPreempted()
+
+	// Late buses go before the other traffic, within the limits of the settings
+	if !preempted {
+		t.tickTransitPriority(currentTick)
+	}
+
+	// Let the strategy change the running calls
+	if updater, ok := t.strategy.(CallUpdater); ok && len(t.currentCalls) > 0 && !preempted && t.transitCall == nil {
+		updater.UpdateCalls(t, currentTick)
 	}
 
 	// Check if we have an current call
@@ -71,16 +125,23 @@ func (t *TrafficController) Tick(currentTick int, try int) {
 		// Check the stop call
 		maxStopTick := 0
 		for _, call := range t.currentCalls {
+			// Check if we should make it GREEN after RED-ORANGE
+			if t.timing.RedOrange > 0 && currentTick == call.greenTick {
+				t.SetLaneState(call, "GREEN")
+			}
+
 			// Check if we should make it ORANGE
 			if currentTick == call.orangeTick {
 				// Get the road and the lane
 				t.SetLaneState(call, "ORANGE")
 			}
 
-			// Check if we should make it RED
+			// Check if we should make it RED, try again on the next tick when the light was not ORANGE long enough.
+			// In SEPERATED mode the ticks of the intersection can run behind the ticks of the traffic controller.
 			if currentTick == call.redTick {
-				// Get the road and the lane
-				t.SetLaneState(call, "RED")
+				if !t.SetLaneState(call, "RED") && t.intersection.GetLaneState(call.roadName, call.laneName) == "ORANGE" {
+					call.redTick++
+				}
 			}
 
 			// Check if this is the max stop tick
@@ -93,151 +154,157 @@ func (t *TrafficController) Tick(currentTick int, try int) {
 		if currentTick == maxStopTick {
 			// Empty the current calls
 			t.currentCalls = []*CurrentCall{}
+			t.transitCall = nil
+			t.clearanceTick = currentTick + t.timing.AllRed
 		}
 	}
-	if len(t.currentCalls) == 0 {
-		// Handle pending calls first
-		if len(t.pendingCalls) > 0 {
-			// Process the first call
-			removedItems := 0
-			for index, call := range t.pendingCalls {
-				// Get data
-				roadName := call[0]
-				laneName := call[1]
-
-				// Check for collison warning
-				if t.intersection.CollisionWarningOnGreen(roadName, laneName) {
-					continue
-				}
 
-				// Remove the pending call from the list
-				t.pendingCalls = append(t.pendingCalls[:(index-removedItems)], t.pendingCalls[(index-removedItems)+1:]...)
-				removedItems++
-
-				// Create a new call
-				newCall := &CurrentCall{
-					roadName:   roadName,
-					laneName:   laneName,
-					greenTick:  currentTick,
-					orangeTick: currentTick + ORANGE_WAIT_TIME,
-					redTick:    currentTick + RED_WAIT_TIME,
-				}
-				t.currentCalls = append(t.currentCalls, newCall)
+	// Let the strategy pick the next lanes when all lights are RED for the all red time
+	if len(t.currentCalls) == 0 && currentTick >= t.clearanceTick && !preempted && !t.startTransitCall(currentTick) {
+		t.strategy.NextCalls(t, currentTick)
+	}
+}
 
-				// Turn on the lights
-				t.SetLaneState(newCall, "GREEN")
-			}
-		} else {
-			// Continue in the patterns
-			// Go back to the first pattern
-			if t.currentPatternIndex >= len(TRAFFIC_PATTERN) {
-				t.currentPatternIndex = 0
-			}
+// takeControl forgets the running calls and makes all lights RED, the strategy starts after the all red time
+func (t *TrafficController) takeControl(currentTick int) {
+	t.currentCalls = []*CurrentCall{}
+	t.transitCall = nil
+	t.intersection.FullStopLights()
+	t.clearanceTick = currentTick + t.timing.AllRed
+}
+
+// StartCall turns a lane GREEN for greenTime ticks, then ORANGE for orangeTime ticks.
+// When RED-ORANGE is configured, the lane is RED-ORANGE first.
+// Crosswalk and bicycle lanes stay ORANGE until everybody had the time to cross.
+func (t *TrafficController) StartCall(roadName string, laneName string, currentTick int, greenTime int, orangeTime int) {
+	// Give humans and bicycles the time to cross
+	if road.IsCrossingLane(laneName) {
+		orangeTime = max(orangeTime, t.intersection.GetCrossingTime(roadName, laneName))
+	}
 
-			// Get the pattern
-			pattern := TRAFFIC_PATTERN[t.currentPatternIndex]
+	// Create a new call
+	greenTick := currentTick + t.timing.RedOrange
+	newCall := &CurrentCall{
+		roadName:   roadName,
+		laneName:   laneName,
+		greenTick:  greenTick,
+		orangeTick: greenTick + greenTime,
+		redTick:    greenTick + greenTime + max(orangeTime, t.timing.GetMinOrange()),
+	}
+	t.currentCalls = append(t.currentCalls, newCall)
 
-			// Make sure we go to the next pattern
-			t.currentPatternIndex++
+	// Turn on the lights
+	if t.timing.RedOrange > 0 {
+		t.SetLaneState(newCall, "RED-ORANGE")
+	} else {
+		t.SetLaneState(newCall, "GREEN")
+	}
+}
 
-			// Remember if we enabled any lights
-			hasNewCurrentCalls := false
+// EndCall turns a lane ORANGE right away, it turns RED after the orange time of the call
+func (t *TrafficController) EndCall(roadName string, laneName string, currentTick int) {
+	// Check if we are in the current call
+	call := t.getCall(roadName, laneName)
+	if call != nil {
+		// The call already ended, or a late bus still needs the GREEN light
+		if currentTick > call.orangeTick || t.holdGreen(call, currentTick) {
+			return
+		}
 
-			// Execute the pattern
-			for _, call := range pattern {
-				// Check if we have the road
-				if !t.intersection.HasLane(call.roadName, call.laneName) {
-					continue
-				}
-				if t.intersection.GetWaitingTrafficByLane(call.roadName, call.laneName) == 0 {
-					continue
-				}
+		// Keep the same orange time
+		orangeTime := call.redTick - call.orangeTick
+		call.orangeTick = currentTick
+		call.redTick = currentTick + orangeTime
+	}
 
-				// Create the call
-				newCall := &CurrentCall{
-					roadName:   call.roadName,
-					laneName:   call.laneName,
-					greenTick:  currentTick,
-					orangeTick: currentTick + ORANGE_WAIT_TIME,
-					redTick:    currentTick + RED_WAIT_TIME,
-				}
-				t.currentCalls = append(t.currentCalls, newCall)
+	// Set the light to ORANGE
+	t.setLaneState(roadName, laneName, "ORANGE")
+}
 
-				// Turn on the lights
-				t.SetLaneState(newCall, "GREEN")
-				hasNewCurrentCalls = true
-			}
+// HasDemand returns whether or not traffic is waiting in the lane, or the button was pressed
+func (t *TrafficController) HasDemand(roadName string, laneName string) bool {
+	if t.intersection.GetWaitingTrafficByLane(roadName, laneName) > 0 {
+		return true
+	}
+	return road.IsCrossingLane(laneName) && t.intersection.IsCalled(roadName, laneName)
+}
 
-			// Check if we have new calls
-			if !hasNewCurrentCalls {
-				// Try again
-				try++
-				t.Tick(currentTick, try)
-			}
+func (t *TrafficController) IsCurrentCall(roadName string, laneName string) bool {
+	for _, call := range t.currentCalls {
+		if call.roadName == roadName && call.laneName == laneName {
+			return true
 		}
 	}
+	return false
 }
 
-func (t *TrafficController) SetLaneState(call *CurrentCall, state string) {
-	t.setLaneState(call.roadName, call.laneName, state)
+func (t *TrafficController) GetIntersection() IntersectionBridge {
+	return t.intersection
 }
 
-func (t *TrafficController) setLaneState(roadName string, laneName string, state string) {
+func (t *TrafficController) GetStrategy() Strategy {
+	return t.strategy
+}
+
+func (t *TrafficController) GetTiming() road.SignalTiming {
+	return t.timing
+}
+
+// GetCounters returns the counters of this traffic controller, the strategy counts its decisions in them
+func (t *TrafficController) GetCounters() *metrics.ControllerCounters {
+	return &t.counters
+}
+
+// GetStatus returns the running calls and why they run
+func (t *TrafficController) GetStatus() ControllerStatus {
+	status := ControllerStatus{Calls: []CallStatus{}, Preempted: t.IsPreempted(), Transit: t.transitCall != nil, Counters: t.counters}
+	for _, call := range t.currentCalls {
+		status.Calls = append(status.Calls, CallStatus{Road: call.roadName, Lane: call.laneName, GreenTick: call.greenTick, OrangeTick: call.orangeTick, RedTick: call.redTick})
+	}
+	return status
+}
+
+// SetLaneState changes the light of the call, returns false when the light didn't change
+func (t *TrafficController) SetLaneState(call *CurrentCall, state string) bool {
+	return t.setLaneState(call.roadName, call.laneName, state)
+}
+
+func (t *TrafficController) setLaneState(roadName string, laneName string, state string) bool {
 	// Log
 	log.Default().Println("TC: Set lane state", roadName, ":", laneName, state)
 
-	// Check for collision warning
-	if state == "GREEN" {
+	// Check for collision warning, the conflict monitor of the intersection is the last line of defense
+	if state == "GREEN" || state == "RED-ORANGE" {
 		result := t.intersection.CollisionWarningOnGreen(roadName, laneName)
 		if result {
-			log.Default().Fatal()
+			t.counters.AddCollisionWarning()
+			log.Default().Println("TC: Collision warning, keeping", roadName, ":", laneName, "RED")
+			return false
 		}
 	}
 
-	// Check if we should set the crosswalk
-	if roadName == "CROSSWALK" || roadName == "BICYCLE" {
-		// Set the crosswalk
-		var roadNames []string = []string{"NORTH", "SOUTH", "EAST", "WEST"}
-		for _, roadName := range roadNames {
-			t.intersection.SetLightState(roadName, roadName, state)
-		}
-
-		// Check if we should set all the lanes (ALL, FORWARD, LEFT, RIGHT)
-	} else {
-		t.intersection.SetLightState(roadName, laneName, state)
-	}
+	// Set the lights, ALL also sets the LEFT, FORWARD and RIGHT lanes
+	return t.intersection.SetLightState(roadName, laneName, state)
 }
 
-func (t *TrafficController) OnRoadTraffic(e event.Event) {
+func (t *TrafficController) OnRoadTraffic(e events.LaneCongested) {
 	// Find the road
-	roadName := e.Get("road").(string)
-	laneName := e.Get("lane").(string)
+	roadName := e.Road
+	laneName := e.Lane
 
 	// Check if the lane is currently green
 	if t.intersection.GetLaneState(roadName, laneName) == "GREEN" {
 		return
 	}
 
-	// Check if we already planned the call
-	isAlreadyPlanned := false
-	for _, call := range t.currentCalls {
-		if call.roadName == roadName && call.laneName == laneName {
-			isAlreadyPlanned = true
-			break
-		}
-	}
-
-	// Plan the green call
-	if !isAlreadyPlanned {
-		t.pendingCalls = append(t.pendingCalls, [2]string{roadName, laneName})
-		log.Default().Println("TC: Planned call for", roadName, ":", laneName)
-	}
+	// Let the strategy decide what to do with it
+	t.strategy.OnRoadTraffic(t, roadName, laneName)
 }
 
-func (t *TrafficController) OnRoadEmpty(e event.Event) {
+func (t *TrafficController) OnRoadEmpty(e events.LaneEmptied) {
 	// Find the road
-	roadName := e.Get("road").(string)
-	laneName := e.Get("lane").(string)
+	roadName := e.Road
+	laneName := e.Lane
 
 	// Check if other lanes have traffic
 	if t.intersection.GetWaitingTrafficByLane(roadName, laneName) > 0 {
@@ -253,36 +320,8 @@ func (t *TrafficController) OnRoadEmpty(e event.Event) {
 		return
 	}
 
-	// Set the light to RED
-	t.setLaneState(roadName, laneName, "ORANGE")
-
-	// Check if we are in the current call
-	for _, call := range t.currentCalls {
-		if call.roadName == roadName && call.laneName == laneName {
-			// Set the light to RED on next tick
-			call.orangeTick = t.intersection.GetCurrentTick()
-			call.redTick = t.intersection.GetCurrentTick() + 1
-			break
-		}
-	}
-}
-
-type TrafficPattern struct {
-	roadName string
-	laneName string
-}
-
-var TRAFFIC_PATTERN [][]*TrafficPattern = [][]*TrafficPattern{
-	{&TrafficPattern{roadName: "CROSSWALK"}, &TrafficPattern{roadName: "BICYCLE"}},
-	{&TrafficPattern{roadName: "NORTH", laneName: "RIGHT"}, &TrafficPattern{roadName: "WEST", laneName: "RIGHT"}, &TrafficPattern{roadName: "WEST", laneName: "LEFT"}},
-	{&TrafficPattern{roadName: "SOUTH", laneName: "ALL"}},
-	{&TrafficPattern{roadName: "NORTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "NORTH", laneName: "RIGHT"}, &TrafficPattern{roadName: "SOUTH", laneName: "FORWARD"}, &TrafficPattern{roadName: "SOUTH", laneName: "RIGHT"}},
-	{&TrafficPattern{roadName: "EAST", laneName: "ALL"}},
-	{&TrafficPattern{roadName: "WEST", laneName: "FORWARD"}, &TrafficPattern{roadName: "WEST", laneName: "RIGHT"}, &TrafficPattern{roadName: "EAST", laneName: "FORWARD"}, &TrafficPattern{roadName: "EAST", laneName: "RIGHT"}},
-	{&TrafficPattern{roadName: "NORTH", laneName: "ALL"}},
-	{&TrafficPattern{roadName: "NORTH", laneName: "LEFT"}, &TrafficPattern{roadName: "SOUTH", laneName: "RIGHT"}},
-	{&TrafficPattern{roadName: "WEST", laneName: "ALL"}},
-	{&TrafficPattern{roadName: "WEST", laneName: "LEFT"}, &TrafficPattern{roadName: "EAST", laneName: "LEFT"}},
+	// Let the strategy decide what to do with it
+	t.strategy.OnRoadEmpty(t, roadName, laneName)
 }
 
 type IntersectionBridge interface {
@@ -292,18 +331,19 @@ type IntersectionBridge interface {
 	CollisionWarningOnGreen(roadName string, laneName string) bool
 	HasLane(roadName string, laneName string) bool
 	GetWaitingTrafficByLane(roadName string, laneName string) int
+	GetArrivedTrafficByLane(roadName string, laneName string) int
 	SetLightState(roadName string, laneName string, state string) bool
 	GetLaneState(roadName string, laneName string) string
+	IsCalled(roadName string, laneName string) bool
+	GetCrossingTime(roadName string, laneName string) int
+	HasEmergencyVehicle(roadName string, laneName string) bool
+	HasBus(roadName string, laneName string) bool
 }
 
 type IntersectionDirectConnection struct {
 	in *intersection.Intersection
 }
 
-type IntersectionApiConnection struct {
-	currentTick int
-}
-
 func (ic *IntersectionDirectConnection) GetCurrentTick() int {
 	return ic.in.CurrentTick
 }
@@ -328,111 +368,69 @@ func (ic *IntersectionDirectConnection) GetWaitingTrafficByLane(roadName string,
 	return ic.in.GetWaitingTrafficByLane(roadName, laneName)
 }
 
-func (ic *IntersectionDirectConnection) SetLightState(roadName string, laneName string, state string) bool {
-	return ic.in.SetLights(roadName, laneName, state)
+func (ic *IntersectionDirectConnection) GetArrivedTrafficByLane(roadName string, laneName string) int {
+	return ic.in.GetArrivedTrafficByLane(roadName, laneName)
 }
 
-func (ic *IntersectionDirectConnection) GetLaneState(roadName string, laneName string) string {
-	return ic.in.GetLaneState(roadName, laneName)
-}
-
-func (ic *IntersectionApiConnection) FullStopLights() {
-	http.Post("http://localhost:8080/stop", "application/json", nil)
-}
-
-func (ic *IntersectionApiConnection) CollisionWarningOnGreen(roadName string, laneName string) bool {
-	result, err := http.Get("http://localhost:8080/road/lane/state?road=" + roadName + "&lane=" + laneName)
+func (ic *IntersectionDirectConnection) SetLightState(roadName string, laneName string, state string) bool {
+	err := ic.in.SetLights(roadName, laneName, road.LightState(state))
 	if err != nil {
+		log.Default().Println("TC: Light change refused:", err)
 		return false
 	}
-	var data bool
-	json.NewDecoder(result.Body).Decode(&data)
-	return data
+	return true
 }
 
-func (ic *IntersectionApiConnection) HasLane(roadName string, laneName string) bool {
-	result, err := http.Get("http://localhost:8080/road/lane?road=" + roadName + "&lane=" + laneName)
-	if err != nil {
-		return false
-	}
-	if result.StatusCode == 200 {
-		return true
-	}
-	return false
+func (ic *IntersectionDirectConnection) GetLaneState(roadName string, laneName string) string {
+	return ic.in.GetLaneState(roadName, laneName)
 }
 
-func (ic *IntersectionApiConnection) GetWaitingTrafficByLane(roadName string, laneName string) int {
-	result, err := http.Get("http://localhost:8080/road/lane?road=" + roadName + "&lane=" + laneName)
-	if err != nil {
-		return 0
-	}
-	type resultDataType struct {
-		Traffic int `json:"traffic"`
-	}
-	var data []resultDataType
-	json.NewDecoder(result.Body).Decode(&data)
-	var totalTraffic int
-	for _, value := range data {
-		totalTraffic += value.Traffic
-	}
-	return totalTraffic
+func (ic *IntersectionDirectConnection) IsCalled(roadName string, laneName string) bool {
+	return ic.in.IsCalled(roadName, laneName)
 }
 
-func (ic *IntersectionApiConnection) SetLightState(roadName string, laneName string, state string) bool {
-	type inputDataType struct {
-		Road  string `json:"road"`
-		Lane  string `json:"lane"`
-		State string `json:"state"`
-	}
-	inputData := inputDataType{Road: roadName, Lane: laneName, State: state}
-	jsonData, _ := json.Marshal(inputData)
-	result, err := http.Post(
-		"http://localhost:8080/road/lane/state",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		return false
-	}
-	if result.StatusCode == 200 {
-		return true
-	}
-	return false
+func (ic *IntersectionDirectConnection) GetCrossingTime(roadName string, laneName string) int {
+	return ic.in.GetCrossingTime(roadName, laneName)
 }
 
-func (ic *IntersectionApiConnection) GetLaneState(roadName string, laneName string) string {
-	result, err := http.Get("http://localhost:8080/road/lane?road=" + roadName + "&lane=" + laneName)
-	if err != nil {
-		return "FLASH"
-	}
-	type resultDataType struct {
-		State string `json:"state"`
-	}
-	var data []resultDataType
-	json.NewDecoder(result.Body).Decode(&data)
-	return data[0].State
+func (ic *IntersectionDirectConnection) HasEmergencyVehicle(roadName string, laneName string) bool {
+	return ic.in.HasEmergencyVehicle(roadName, laneName)
 }
 
-func (ic *IntersectionApiConnection) GetCurrentTick() int {
-	return ic.currentTick
+func (ic *IntersectionDirectConnection) HasBus(roadName string, laneName string) bool {
+	return ic.in.HasBus(roadName, laneName)
 }
 
-func (ic *IntersectionApiConnection) SetCurrentTick(tick int) {
-	ic.currentTick = tick
-}
+// StartTrafficControllerSeperated runs the traffic controller next to the API, it changes the lights with the API.
+// The events still come from the bus of the intersection, both run in the same program.
+func StartTrafficControllerSeperated(tickSpeed time.Duration, connection ConnectionSettings, bus *events.Bus, strategy Strategy, timing road.SignalTiming, transit TransitPrioritySettings) {
+	// The events are published while the intersection is locked, keep them until the loop of the TrafficController
+	// handles them, its handlers call the API
+	queue := events.NewQueue(bus)
+	defer queue.Close()
 
-func StartTrafficControllerSeperated(tickSpeed time.Duration) {
 	// Create the TrafficController
-	tc := NewTrafficController("SEPERATED", nil)
+	api := NewIntersectionApiConnection(connection)
+	tc := newTrafficController(api, queue.GetBus(), strategy, timing)
+	tc.SetTransitPriority(transit)
 
 	// Create the loop
 	currentTick := 0
 	for {
 		// Set the current tick in the intersection connection
-		tc.intersection.SetCurrentTick(currentTick)
+		api.SetCurrentTick(currentTick)
+
+		// Tell the watchdog of the intersection the TrafficController is still there,
+		// take control again when the watchdog lost it and the lights FLASH
+		failSafe, err := api.Heartbeat(tc.GetStatus())
+		if err == nil && failSafe && currentTick > 0 {
+			log.Default().Println("TC: The intersection lost the traffic controller, taking control again on tick", currentTick)
+			tc.takeControl(currentTick)
+		}
 
-		// Update the TrafficController
-		tc.Tick(currentTick, 0)
+		// Handle the events of the intersection and update the TrafficController
+		queue.Deliver()
+		tc.Tick(currentTick)
 
 		// Increase the tick
 		currentTick++
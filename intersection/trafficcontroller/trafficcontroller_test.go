@@ -0,0 +1,51 @@
+package trafficcontroller
+
+import (
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/road"
+)
+
+// lateIntersection refuses RED the first time, like an intersection that runs behind the traffic controller
+type lateIntersection struct {
+	*fakeIntersection
+	refused bool
+}
+
+func (l *lateIntersection) SetLightState(roadName string, laneName string, state string) bool {
+	if state == "RED" && !l.refused {
+		l.refused = true
+		return false
+	}
+	return l.fakeIntersection.SetLightState(roadName, laneName, state)
+}
+
+func TestRedIsRetried(t *testing.T) {
+	in := &lateIntersection{fakeIntersection: newFakeIntersection()}
+	in.waiting["NORTH:LEFT"] = 0
+	tc := &TrafficController{intersection: in, strategy: NewPatternStrategy()}
+	tc.StartCall("NORTH", "LEFT", 0, 1, 1)
+	for tick := 1; tick <= 3; tick++ {
+		tc.Tick(tick)
+	}
+	if in.states["NORTH:LEFT"] != "RED" {
+		t.Fatalf(`Light should turn RED on the next tick after it was refused, got %s`, in.states["NORTH:LEFT"])
+	}
+}
+
+func TestCloseUnsubscribes(t *testing.T) {
+	in := intersection.NewIntersection([]*road.Road{road.NewRoad(road.NewRoadInput{Name: "NORTH", Forward: 1})})
+	before := in.GetEvents().GetSubscriptionCount()
+	tc := NewTrafficController(in, NewPatternStrategy(), road.SignalTiming{})
+	if in.GetEvents().GetSubscriptionCount() == before {
+		t.Fatalf(`Traffic controller should listen to the events of the intersection`)
+	}
+
+	// A closed traffic controller no longer hears about emergency vehicles
+	tc.Close()
+	in.AddEmergencyVehicle("NORTH", "FORWARD")
+	if in.GetEvents().GetSubscriptionCount() != before || tc.IsPreempted() {
+		t.Fatalf(`Traffic controller should stop listening after Close`)
+	}
+}
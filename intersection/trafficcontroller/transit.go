@@ -0,0 +1,167 @@
+package trafficcontroller
+
+import (
+	"log"
+
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+)
+
+const DEFAULT_MAX_EXTENSION int = 10
+const DEFAULT_MAX_REDUCTION int = 10
+
+// A lane that crosses the bus lane stays GREEN at least this long, even when a late bus is waiting
+const TRANSIT_MIN_GREEN int = 3
+
+// TransitPrioritySettings are the settings of the transit priority in the config, 0 for the default
+type TransitPrioritySettings struct {
+	Enabled      bool `json:"enabled"`      // Whether or not late buses get priority
+	MinLateness  int  `json:"minLateness"`  // A bus gets priority when it is more than this amount of ticks late
+	MaxExtension int  `json:"maxExtension"` // Ticks the GREEN of the bus lane is extended at most
+	MaxReduction int  `json:"maxReduction"` // Ticks the GREEN of a lane that crosses the bus lane is shortened at most
+}
+
+// TransitRequest is a late bus that waits for GREEN, the bus lane is extended when it is GREEN,
+// otherwise the lanes that cross it are shortened and the bus lane goes next.
+type TransitRequest struct {
+	roadName  string
+	laneName  string
+	lateness  int
+	startTick int
+	extended  int  // Ticks the GREEN of the bus lane was extended
+	reduced   bool // Whether or not the lanes that cross the bus lane were shortened
+	served    bool // Whether or not the bus lane went before the strategy, this happens once
+}
+
+// SetTransitPriority turns on the priority for late buses
+func (t *TrafficController) SetTransitPriority(settings TransitPrioritySettings) {
+	if settings.MaxExtension <= 0 {
+		settings.MaxExtension = DEFAULT_MAX_EXTENSION
+	}
+	if settings.MaxReduction <= 0 {
+		settings.MaxReduction = DEFAULT_MAX_REDUCTION
+	}
+	t.transit = settings
+}
+
+// RequestTransitPriority plans the priority for a bus, buses that are not late enough wait like the other traffic
+func (t *TrafficController) RequestTransitPriority(roadName string, laneName string, lateness int, currentTick int) {
+	if !t.transit.Enabled || lateness <= t.transit.MinLateness {
+		return
+	}
+
+	// One request for every lane, the latest bus counts
+	for _, r := range t.transitRequests {
+		if r.roadName == roadName && r.laneName == laneName {
+			r.lateness = max(r.lateness, lateness)
+			return
+		}
+	}
+	log.Default().Println("TC: Transit priority requested for", roadName, ":", laneName, "bus is", lateness, "ticks late")
+	t.transitRequests = append(t.transitRequests, &TransitRequest{roadName: roadName, laneName: laneName, lateness: lateness, startTick: currentTick})
+}
+
+func (t *TrafficController) OnBus(e events.BusArrived) {
+	t.RequestTransitPriority(e.Road, e.Lane, e.Lateness, t.intersection.GetCurrentTick())
+}
+
+func (t *TrafficController) tickTransitPriority(currentTick int) {
+	var requests []*TransitRequest
+	for _, r := range t.transitRequests {
+		// Forget the request when the bus crossed
+		if !t.intersection.HasBus(r.roadName, r.laneName) {
+			log.Default().Println("TC: Bus crossed", r.roadName, ":", r.laneName, "after", currentTick-r.startTick, "ticks")
+			continue
+		}
+		requests = append(requests, r)
+
+		// Keep the bus lane GREEN a little longer, also the bus lane that went before the strategy
+		call := t.getCall(r.roadName, r.laneName)
+		if call != nil && currentTick <= call.orangeTick {
+			if currentTick == call.orangeTick && r.extended < t.transit.MaxExtension {
+				call.orangeTick++
+				call.redTick++
+				r.extended++
+				t.counters.AddTransitExtension(1)
+				log.Default().Println("TC: Transit priority, extending", r.roadName, ":", r.laneName, "GREEN for", r.extended, "ticks")
+			}
+			continue
+		}
+
+		// Shorten the lanes that cross the bus lane once, the bus lane goes next
+		if !r.reduced {
+			r.reduced = true
+			t.reduceConflictingCalls(r, currentTick)
+		}
+	}
+	t.transitRequests = requests
+}
+
+// reduceConflictingCalls ends the GREEN of the lanes that cross the bus lane sooner, within the max reduction
+func (t *TrafficController) reduceConflictingCalls(r *TransitRequest, currentTick int) {
+	bus := collisonwarning.Movement{Road: r.roadName, Lane: r.laneName}
+	for _, call := range t.currentCalls {
+		if currentTick >= call.orangeTick || !collisonwarning.HasConflict(bus, collisonwarning.Movement{Road: call.roadName, Lane: call.laneName}) {
+			continue
+		}
+		reduction := min(t.transit.MaxReduction, call.orangeTick-max(currentTick, call.greenTick+TRANSIT_MIN_GREEN))
+		if reduction <= 0 {
+			continue
+		}
+		call.orangeTick -= reduction
+		call.redTick -= reduction
+		t.counters.AddTransitReduction(reduction)
+		log.Default().Println("TC: Transit priority, shortening", call.roadName, ":", call.laneName, "GREEN by", reduction, "ticks")
+	}
+}
+
+// holdGreen keeps the bus lane GREEN when the strategy ends it while a late bus still waits
+func (t *TrafficController) holdGreen(call *CurrentCall, currentTick int) bool {
+	for _, r := range t.transitRequests {
+		if r.roadName != call.roadName || r.laneName != call.laneName || r.extended >= t.transit.MaxExtension {
+			continue
+		}
+		if currentTick < call.greenTick || currentTick >= call.orangeTick {
+			return false
+		}
+
+		// The light turns ORANGE on the next tick, unless the bus is still waiting
+		orangeTime := call.redTick - call.orangeTick
+		call.orangeTick = currentTick + 1
+		call.redTick = call.orangeTick + orangeTime
+		r.extended++
+		t.counters.AddTransitExtension(1)
+		return true
+	}
+	return false
+}
+
+// startTransitCall gives the lane of the first late bus GREEN before the strategy picks the next lanes
+func (t *TrafficController) startTransitCall(currentTick int) bool {
+	for _, r := range t.transitRequests {
+		if r.served || t.intersection.CollisionWarningOnGreen(r.roadName, r.laneName) {
+			continue
+		}
+		log.Default().Println("TC: Transit priority, turning", r.roadName, ":", r.laneName, "GREEN for a bus that is", r.lateness, "ticks late")
+		t.StartCall(r.roadName, r.laneName, currentTick, 1, RED_WAIT_TIME-ORANGE_WAIT_TIME)
+
+		// Let the strategy pick the lanes when the intersection refused the light
+		if t.intersection.GetLaneState(r.roadName, r.laneName) == "RED" {
+			t.currentCalls = t.currentCalls[:len(t.currentCalls)-1]
+			return false
+		}
+		r.served = true
+		t.transitCall = t.currentCalls[len(t.currentCalls)-1]
+		return true
+	}
+	return false
+}
+
+func (t *TrafficController) getCall(roadName string, laneName string) *CurrentCall {
+	for _, call := range t.currentCalls {
+		if call.roadName == roadName && call.laneName == laneName {
+			return call
+		}
+	}
+	return nil
+}
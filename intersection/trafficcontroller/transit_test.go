@@ -0,0 +1,122 @@
+package trafficcontroller
+
+import (
+	"testing"
+
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
+)
+
+func TestTransitPriorityExtendsGreen(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:FORWARD"] = 30
+	in.bus["NORTH:FORWARD"] = true
+	tc := &TrafficController{intersection: in, strategy: NewPatternStrategy()}
+	tc.SetTransitPriority(TransitPrioritySettings{Enabled: true, MaxExtension: 5})
+
+	// The bus waits at the back of the queue, so NORTH stays GREEN until the max extension
+	var north []string
+	for tick := 0; tick < 17; tick++ {
+		in.currentTick = tick
+		in.tick()
+		if tick == 1 {
+			tc.RequestTransitPriority("NORTH", "FORWARD", 20, tick)
+		}
+		tc.Tick(tick)
+		north = append(north, in.states["NORTH:FORWARD"])
+	}
+	if north[14] != "GREEN" || north[15] != "ORANGE" {
+		t.Fatalf(`NORTH should stay GREEN 5 ticks longer for the bus, got %v`, north)
+	}
+}
+
+func TestTransitPriorityLimitsBusLaneBeforeStrategy(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["NORTH:FORWARD"] = 30
+	in.bus["NORTH:FORWARD"] = true
+	tc := &TrafficController{intersection: in, strategy: NewPatternStrategy()}
+	tc.SetTransitPriority(TransitPrioritySettings{Enabled: true, MaxExtension: 3})
+	tc.RequestTransitPriority("NORTH", "FORWARD", 20, 0)
+
+	// The bus lane goes before the strategy, it is GREEN for 1 tick and the max extension
+	var north []string
+	for tick := 0; tick < 8; tick++ {
+		in.currentTick = tick
+		in.tick()
+		tc.Tick(tick)
+		north = append(north, in.states["NORTH:FORWARD"])
+	}
+	if north[0] != "GREEN" || north[3] != "GREEN" || north[4] != "ORANGE" {
+		t.Fatalf(`NORTH should be GREEN for 4 ticks, got %v`, north)
+	}
+}
+
+func TestTransitPriorityShortensConflictingGreen(t *testing.T) {
+	in := newFakeIntersection()
+	in.waiting["WEST:FORWARD"] = 30
+	in.waiting["NORTH:LEFT"] = 5
+	in.states["NORTH:LEFT"] = "RED"
+	tc := &TrafficController{intersection: in, strategy: NewPatternStrategy()}
+	tc.SetTransitPriority(TransitPrioritySettings{Enabled: true, MinLateness: 5})
+
+	var west, north []string
+	for tick := 0; tick < 8; tick++ {
+		in.currentTick = tick
+		in.tick()
+
+		// A bus that is on time waits like the other traffic, a late bus gets priority
+		if tick == 2 {
+			in.bus["NORTH:LEFT"] = true
+			tc.RequestTransitPriority("NORTH", "LEFT", 5, tick)
+		}
+		if tick == 4 {
+			tc.RequestTransitPriority("NORTH", "LEFT", 20, tick)
+		}
+		tc.Tick(tick)
+		west = append(west, in.states["WEST:FORWARD"])
+		north = append(north, in.states["NORTH:LEFT"])
+	}
+
+	// WEST crosses the bus, it stays GREEN for the min green and NORTH goes next
+	if west[3] != "GREEN" || west[4] != "ORANGE" || west[5] != "RED" {
+		t.Fatalf(`WEST should be shortened for the late bus, got %v`, west)
+	}
+	if north[5] != "GREEN" {
+		t.Fatalf(`NORTH should go next for the late bus, got %v`, north)
+	}
+}
+
+func TestTransitPriorityReducesBusDelay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the bus scenario with every strategy")
+	}
+
+	// The same seed gives the same traffic, only the priority is different
+	for name, newStrategy := range STRATEGIES {
+		without := runBusScenario(newStrategy(StrategySettings{}), 1, 5000, 150, TransitPrioritySettings{})
+		with := runBusScenario(newStrategy(StrategySettings{}), 1, 5000, 150, TransitPrioritySettings{Enabled: true})
+		if without.Buses == 0 || with.Buses == 0 {
+			t.Fatalf(`%s: buses should cross, got %d and %d`, name, without.Buses, with.Buses)
+		}
+		if with.BusAverageDelay >= without.BusAverageDelay {
+			t.Fatalf(`%s: transit priority should lower the bus delay, got %.1f with and %.1f without`, name, with.BusAverageDelay, without.BusAverageDelay)
+		}
+	}
+}
+
+func benchmarkTransitScenario(b *testing.B, transit TransitPrioritySettings) {
+	var summary metrics.Summary
+	for i := 0; i < b.N; i++ {
+		summary = runBusScenario(NewMaxPressureStrategy(StrategySettings{}), 1, 5000, 150, transit)
+	}
+	b.ReportMetric(summary.BusAverageDelay, "bus-delay-ticks")
+	b.ReportMetric(summary.AverageDelay, "avg-delay-ticks")
+}
+
+// Compare the bus delay with: go test -bench Transit ./trafficcontroller
+func BenchmarkWithoutTransitPriority(b *testing.B) {
+	benchmarkTransitScenario(b, TransitPrioritySettings{})
+}
+
+func BenchmarkWithTransitPriority(b *testing.B) {
+	benchmarkTransitScenario(b, TransitPrioritySettings{Enabled: true})
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/martijnwiekens/go-learning/gointersection/events"
+)
+
+// EventLog keeps the events of the intersection since the last print, they are printed below the intersection
+type EventLog struct {
+	lines        []string
+	crossed      int
+	subscription *events.Subscription
+}
+
+func NewEventLog(bus *events.Bus) *EventLog {
+	l := &EventLog{}
+	l.subscription = events.SubscribeAll(bus, l.record)
+	return l
+}
+
+func (l *EventLog) record(e any) {
+	switch e := e.(type) {
+	case events.VehicleCrossed:
+		// Only count them, there are too many to print
+		l.crossed++
+	case events.LightChanged:
+		l.lines = append(l.lines, fmt.Sprintf("%s:%s %s -> %s", e.Road, e.Lane, e.From, e.State))
+	case events.LaneCongested:
+		l.lines = append(l.lines, fmt.Sprintf("%s:%s has a lot of traffic", e.Road, e.Lane))
+	case events.LaneEmptied:
+		l.lines = append(l.lines, fmt.Sprintf("%s:%s is empty", e.Road, e.Lane))
+	case events.EmergencyVehicleArrived:
+		l.lines = append(l.lines, fmt.Sprintf("%s:%s has an emergency vehicle", e.Road, e.Lane))
+	case events.BusArrived:
+		l.lines = append(l.lines, fmt.Sprintf("%s:%s has a bus that is %d ticks late", e.Road, e.Lane, e.Lateness))
+	}
+}
+
+// Print shows the events since the last print
+func (l *EventLog) Print() {
+	fmt.Println("Events:", l.crossed, "crossed")
+	for _, line := range l.lines {
+		fmt.Println("  " + line)
+	}
+	l.lines = nil
+	l.crossed = 0
+}
+
+// Close stops keeping the events
+func (l *EventLog) Close() {
+	l.subscription.Unsubscribe()
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/martijnwiekens/go-learning/gointersection/collisonwarning"
 	"github.com/martijnwiekens/go-learning/gointersection/intersection"
+	"github.com/martijnwiekens/go-learning/gointersection/metrics"
 	"github.com/martijnwiekens/go-learning/gointersection/road"
 )
 
@@ -23,6 +25,91 @@ func PrintTotalCarsWaiting(i *intersection.Intersection) {
 	fmt.Println("Total cars waiting: ", totalCarsWaiting)
 }
 
+func PrintSummary(i *intersection.Intersection) {
+	fmt.Println("=========================================")
+	if i.GetName() != "" {
+		fmt.Println("Intersection", i.GetName())
+	}
+	fmt.Println("Summary after", i.CurrentTick+1, "ticks")
+	fmt.Println()
+	fmt.Printf("%-20s %8s %9s %9s %11s %9s %7s %7s %7s %9s\n", "", "Vehicles", "Avg delay", "P95 delay", "Throughput", "Max queue", "Greens", "Green", "Wasted", "Stops/veh")
+	for _, r := range i.GetRoads() {
+		printSummaryLine(r.GetName(), r.GetMetrics())
+		for _, l := range r.GetLanes() {
+			if l.GetDirection() == "OUTPUT" {
+				continue
+			}
+			printSummaryLine("  "+l.GetDirection(), l.GetMetrics())
+		}
+	}
+	summary := i.GetMetrics()
+	printSummaryLine("INTERSECTION", summary)
+	fmt.Println()
+	fmt.Println("Delay in ticks, throughput in vehicles per", metrics.THROUGHPUT_WINDOW, "ticks, green and wasted green in ticks")
+	fmt.Println("Stops/veh is the part of the vehicles that stopped for the light")
+
+	// Show the delay of the buses
+	if summary.Buses > 0 {
+		fmt.Printf("Buses: %d crossed with an average delay of %.1f ticks\n", summary.Buses, summary.BusAverageDelay)
+	}
+
+	// Show where the traffic drove
+	fmt.Println()
+	printODMatrix(i.GetODMatrix())
+
+	// Show the faults of the conflict monitor
+	for _, fault := range i.GetFaults() {
+		fmt.Println("Conflict on tick", fault.Tick, "between", fault.Road, ":", fault.Lane, "and", fault.ConflictRoad, ":", fault.ConflictLane)
+	}
+}
+
+// printODMatrix shows the vehicles that drove from a road (row) to a road (column)
+func printODMatrix(matrix *metrics.ODMatrix) {
+	fmt.Printf("%-20s", "From \\ To")
+	for _, destination := range collisonwarning.MATRIX_ROADS {
+		fmt.Printf(" %8s", destination)
+	}
+	fmt.Printf(" %8s\n", "Total")
+	totals := map[string]int{}
+	for _, origin := range collisonwarning.MATRIX_ROADS {
+		fmt.Printf("%-20s", origin)
+		total := 0
+		for _, destination := range collisonwarning.MATRIX_ROADS {
+			count := matrix.Get(origin, destination)
+			fmt.Printf(" %8d", count)
+			total += count
+			totals[destination] += count
+		}
+		fmt.Printf(" %8d\n", total)
+	}
+	fmt.Printf("%-20s", "Total")
+	total := 0
+	for _, destination := range collisonwarning.MATRIX_ROADS {
+		fmt.Printf(" %8d", totals[destination])
+		total += totals[destination]
+	}
+	fmt.Printf(" %8d\n", total)
+	fmt.Println()
+	fmt.Println("Vehicles that crossed from the road of the row to the road of the column")
+}
+
+func printSummaryLine(name string, summary metrics.Summary) {
+	fmt.Printf("%-20s %8d %9.1f %9d %11.1f %9d %7d %7d %7d %9.2f\n", name, summary.Vehicles, summary.AverageDelay, summary.P95Delay, summary.Throughput, summary.MaxQueue, summary.GreenPhases, summary.GreenTicks, summary.WastedGreenTicks, summary.StopsPerVehicle)
+}
+
+// PrintTripSummary shows the stops of the vehicles that left a network of intersections
+func PrintTripSummary(trips *metrics.TripRecorder) {
+	fmt.Println("=========================================")
+	fmt.Println("Trips through the network")
+	fmt.Println()
+	fmt.Printf("%-20s %8s %8s %10s\n", "Intersections", "Vehicles", "Stops", "Stops/trip")
+	for _, trip := range trips.GetSummaries() {
+		fmt.Printf("%-20d %8d %8d %10.2f\n", trip.Intersections, trip.Trips, trip.Stops, trip.StopsPerTrip)
+	}
+	fmt.Println()
+	fmt.Println("A vehicle that crossed every intersection drove the whole corridor")
+}
+
 func PrintIntersection(i *intersection.Intersection) {
 	/**
 		2 lanes (west, east)
@@ -243,13 +330,29 @@ func getEastRoadOutput(r *road.Road) []string {
 }
 
 func getTrafficLight(l *road.Lane) string {
+	// Crosswalks show walk and don't walk
+	if l.GetDirection() == "CROSSWALK" {
+		switch l.GetSignal() {
+		case road.SIGNAL_WALK:
+			return "W"
+		case road.SIGNAL_FLASHING_DONT_WALK:
+			return "!"
+		case road.SIGNAL_DONT_WALK:
+			return "D"
+		}
+	}
+
 	trafficLight := "F"
-	if l.GetState() == "GREEN" {
+	if l.GetState() == road.STATE_GREEN {
 		trafficLight = "O"
-	} else if l.GetState() == "ORANGE" {
+	} else if l.GetState() == road.STATE_ORANGE {
 		trafficLight = "E"
-	} else if l.GetState() == "RED" {
+	} else if l.GetState() == road.STATE_RED_ORANGE {
+		trafficLight = "R"
+	} else if l.GetState() == road.STATE_RED {
 		trafficLight = "X"
+	} else if l.GetState() == road.STATE_OFF {
+		trafficLight = " "
 	}
 	return trafficLight
 }
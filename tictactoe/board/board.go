@@ -3,8 +3,9 @@ package board
 import "fmt"
 
 type Board struct {
-	board [][]uint8
-	size  uint8
+	board               [][]uint8
+	size                uint8
+	winningCombinations [][][2]uint8 // All lines of places that win the game, only depends on the size
 }
 
 func NewBoard(boardSize uint8) *Board {
@@ -14,10 +15,29 @@ func NewBoard(boardSize uint8) *Board {
 		board[i] = make([]uint8, boardSize)
 	}
 
-	return &Board{
+	playBoard := &Board{
 		board: board,
 		size:  boardSize,
 	}
+
+	// Calculate the winning combinations once
+	playBoard.winningCombinations = playBoard.createWinningCombinations()
+	return playBoard
+}
+
+func (playBoard *Board) Copy() *Board {
+	// Copy the places, the winning combinations never change so they can be shared
+	board := make([][]uint8, playBoard.size)
+	for i := range board {
+		board[i] = make([]uint8, playBoard.size)
+		copy(board[i], playBoard.board[i])
+	}
+
+	return &Board{
+		board:               board,
+		size:                playBoard.size,
+		winningCombinations: playBoard.winningCombinations,
+	}
 }
 
 func (playBoard *Board) GetBoard() [][]uint8 {
@@ -48,6 +68,31 @@ func (playBoard *Board) IsFull() bool {
 }
 
 func (playBoard *Board) CheckWin(player uint8) bool {
+	return playBoard.GetWinningLine(player) != nil
+}
+
+func (playBoard *Board) GetWinningLine(player uint8) [][2]uint8 {
+	// Check each winning combination
+	// The same player should have all places in the a combination
+	for _, winningCombination := range playBoard.winningCombinations {
+		countPlaces := 0
+		for _, place := range winningCombination {
+			if playBoard.GetPosition(place[0], place[1]) == player {
+				countPlaces++
+			}
+		}
+		if countPlaces == playBoard.GetBoardSize() {
+			return winningCombination
+		}
+	}
+	return nil
+}
+
+func (playBoard *Board) GetWinningCombinations() [][][2]uint8 {
+	return playBoard.winningCombinations
+}
+
+func (playBoard *Board) createWinningCombinations() [][][2]uint8 {
 	// Create list of possible winning combinations
 	/*
 				[
@@ -76,7 +121,7 @@ func (playBoard *Board) CheckWin(player uint8) bool {
 	if len(winningCombinations) != boardSize {
 		fmt.Println("Error: winningCombinations #1 is not the same length as boardSize")
 		fmt.Println(winningCombinations)
-		return false
+		return nil
 	}
 
 	// Second up: columns
@@ -91,7 +136,7 @@ func (playBoard *Board) CheckWin(player uint8) bool {
 	if len(winningCombinations) != boardSize*2 {
 		fmt.Println("Error: winningCombinations #2 is not the same length as boardSize")
 		fmt.Println(winningCombinations)
-		return false
+		return nil
 	}
 
 	// Third up: diagonals
@@ -110,26 +155,10 @@ func (playBoard *Board) CheckWin(player uint8) bool {
 	}
 	winningCombinations = append(winningCombinations, rowCombinations2)
 
-	// Check each winning combination
-	// The same player should have all places in the a combination
-
 	if len(winningCombinations) != ((boardSize * 2) + 2) {
 		fmt.Println("Error: winningCombinations #3 is not the same length as boardSize")
 		fmt.Println(winningCombinations)
-		return false
-	}
-
-	// Check each winning combination
-	// The same player should have all places in the a combination
-	for _, winningCombination := range winningCombinations {
-		countPlaces := [3]int{0, 0, 0}
-		for _, place := range winningCombination {
-			holder := playBoard.GetPosition(place[0], place[1])
-			countPlaces[holder]++
-		}
-		if countPlaces[player] == boardSize {
-			return true
-		}
+		return nil
 	}
-	return false
+	return winningCombinations
 }
@@ -1,7 +1,78 @@
 package main
 
-import "github.com/martijnwiekens/go-learning/tictactoe/game"
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/martijnwiekens/go-learning/tictactoe/game"
+	"github.com/martijnwiekens/go-learning/tictactoe/profiles"
+	"github.com/martijnwiekens/go-learning/tictactoe/ui"
+)
 
 func main() {
-	game.StartGame(3, true)
+	// Read the command line options
+	fullScreen := flag.Bool("fullscreen", false, "Play in full screen with the arrow keys")
+	clockTime := flag.Duration("time", 0, "Time on the clock of each player, like 1m (default no clock)")
+	increment := flag.Duration("increment", 0, "Time added to the clock after each move, like 2s")
+	names := flag.String("names", "", "Names of the players for the statistics, separated by a comma")
+	profilesPath := flag.String("profiles", "./tictactoe-profiles.json", "File to keep the player profiles in")
+	leaderboard := flag.Bool("leaderboard", false, "Show the leaderboard and quit")
+	playerCount := flag.Int("players", 2, "Number of players, 2 to 4")
+	humans := flag.Int("humans", 1, "Number of human players, the other players are played by the AI")
+	symbols := flag.String("symbols", "", "Symbol and color of every player, like X:RED,O:YELLOW,#:GREEN")
+	boardSize := flag.Int("size", 0, "Size of the board (default a larger board for more players)")
+	flag.Parse()
+
+	// Create the players
+	players, err := game.NewPlayerSettings(*playerCount, *humans, *symbols)
+	if err != nil {
+		fmt.Println("Can't create the players:", err)
+		os.Exit(2)
+	}
+	for index, name := range strings.Split(*names, ",") {
+		if index < len(players) {
+			players[index].Name = strings.TrimSpace(name)
+		}
+	}
+
+	// Find the size of the board
+	if *boardSize == 0 {
+		*boardSize = int(game.GetDefaultBoardSize(len(players)))
+	}
+	if err := game.ValidateBoardSize(*boardSize); err != nil {
+		fmt.Println("Can't create the board:", err)
+		os.Exit(2)
+	}
+
+	// Load the player profiles
+	store, err := profiles.Load(*profilesPath)
+	if err != nil {
+		fmt.Println("Can't load the player profiles:", err)
+	} else {
+		game.SetProfileStore(store)
+	}
+
+	// Only show the leaderboard
+	if *leaderboard {
+		if store != nil {
+			ui.PrintLeaderboard(store.GetLeaderboard())
+		}
+		return
+	}
+
+	// Set the clocks
+	game.SetTimeControl(game.TimeControl{Time: *clockTime, Increment: *increment})
+
+	// Switch to the full screen mode
+	if *fullScreen {
+		err := ui.EnableFullScreen()
+		if err != nil {
+			fmt.Println("Can't start full screen mode:", err)
+		}
+		defer ui.DisableFullScreen()
+	}
+
+	game.StartMultiplayerGame(uint8(*boardSize), players)
 }
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/martijnwiekens/go-learning/tictactoe/board"
+)
+
+// searchRootMoves tries every possible move for the player and scores it with the search.
+// The moves are split over the workers, each worker plays on its own copy of the board.
+// It returns the first move with the best score, just like a search on a single goroutine.
+func (aiPlayer *AIPlayer) searchRootMoves(playBoard *board.Board, player uint8, search func(b *board.Board) int) (uint8, uint8) {
+	// Find all possible moves
+	var moves [][2]uint8
+	for i := 0; i < playBoard.GetBoardSize(); i++ {
+		for j := 0; j < playBoard.GetBoardSize(); j++ {
+			if playBoard.GetPosition(uint8(i), uint8(j)) == EMPTY {
+				moves = append(moves, [2]uint8{uint8(i), uint8(j)})
+			}
+		}
+	}
+
+	// Find how many workers we need
+	workers := aiPlayer.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(moves))
+
+	// Every worker takes the next move from the channel
+	scores := make([]int, len(moves))
+	nextMoves := make(chan int, len(moves))
+	for index := range moves {
+		nextMoves <- index
+	}
+	close(nextMoves)
+
+	// Start the workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workerBoard := playBoard.Copy()
+			for index := range nextMoves {
+				move := moves[index]
+				workerBoard.SetPosition(move[0], move[1], player)
+				scores[index] = search(workerBoard)
+				workerBoard.SetPosition(move[0], move[1], EMPTY)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Find the best move
+	bestScore := -1000
+	var move [2]uint8
+	for index, score := range scores {
+		if score > bestScore {
+			bestScore = score
+			move = moves[index]
+		}
+	}
+	return move[0], move[1]
+}
@@ -0,0 +1,196 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+)
+
+const (
+	RESULT_WIN  = "WIN"
+	RESULT_LOSS = "LOSS"
+	RESULT_DRAW = "DRAW"
+)
+
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+type Profile struct {
+	Name             string             `json:"name"`
+	GamesPlayed      int                `json:"gamesPlayed"`
+	Total            Record             `json:"total"`
+	CurrentStreak    int                `json:"currentStreak"`    // Positive for wins in a row, negative for losses in a row
+	LongestWinStreak int                `json:"longestWinStreak"` // Most wins in a row ever
+	Opponents        map[string]*Record `json:"opponents"`        // Results against other human players
+	AiLevels         map[string]*Record `json:"aiLevels"`         // Results against each AI mode
+}
+
+type Participant struct {
+	Name    string // Name of the player
+	AiLevel string // Mode of the AI, empty for a human player
+}
+
+type Store struct {
+	path     string
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Profiles: map[string]*Profile{}}
+
+	// Read the file, a new store is empty
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the profiles
+	err = json.Unmarshal(data, store)
+	if err != nil {
+		return nil, err
+	}
+	if store.Profiles == nil {
+		store.Profiles = map[string]*Profile{}
+	}
+	for _, profile := range store.Profiles {
+		if profile.Opponents == nil {
+			profile.Opponents = map[string]*Record{}
+		}
+		if profile.AiLevels == nil {
+			profile.AiLevels = map[string]*Record{}
+		}
+	}
+	return store, nil
+}
+
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so we never end up with half a file
+	err = os.WriteFile(s.path+".tmp", data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(s.path+".tmp", s.path)
+}
+
+func (s *Store) GetProfile(name string) *Profile {
+	// Create a new profile when we don't know the player
+	profile, ok := s.Profiles[name]
+	if !ok {
+		profile = &Profile{
+			Name:      name,
+			Opponents: map[string]*Record{},
+			AiLevels:  map[string]*Record{},
+		}
+		s.Profiles[name] = profile
+	}
+	return profile
+}
+
+// RecordGame updates the profile of every human player in a finished game.
+// The winner and loser are indexes in the participants, -1 when there is none.
+// A loser without a winner happens when a player runs out of time in a game with more players.
+func (s *Store) RecordGame(participants []Participant, winner int, loser int) {
+	for index, participant := range participants {
+		// The AI has no profile
+		if participant.AiLevel != "" {
+			continue
+		}
+
+		// Find the result of this player
+		result := RESULT_DRAW
+		if index == winner {
+			result = RESULT_WIN
+		} else if winner >= 0 || index == loser {
+			result = RESULT_LOSS
+		}
+
+		// Update the totals
+		profile := s.GetProfile(participant.Name)
+		profile.GamesPlayed++
+		profile.Total.add(result)
+		profile.updateStreak(result)
+
+		// Update the results against every opponent
+		for opponentIndex, opponent := range participants {
+			if opponentIndex == index {
+				continue
+			}
+			if opponent.AiLevel != "" {
+				getRecord(profile.AiLevels, opponent.AiLevel).add(result)
+			} else {
+				getRecord(profile.Opponents, opponent.Name).add(result)
+			}
+		}
+	}
+}
+
+func (s *Store) GetLeaderboard() []*Profile {
+	var leaderboard []*Profile
+	for _, profile := range s.Profiles {
+		leaderboard = append(leaderboard, profile)
+	}
+
+	// Most wins first, then the best win rate, then by name
+	sort.Slice(leaderboard, func(i, j int) bool {
+		a, b := leaderboard[i], leaderboard[j]
+		if a.Total.Wins != b.Total.Wins {
+			return a.Total.Wins > b.Total.Wins
+		}
+		if a.GetWinRate() != b.GetWinRate() {
+			return a.GetWinRate() > b.GetWinRate()
+		}
+		return a.Name < b.Name
+	})
+	return leaderboard
+}
+
+func (p *Profile) GetWinRate() float64 {
+	if p.GamesPlayed == 0 {
+		return 0
+	}
+	return float64(p.Total.Wins) / float64(p.GamesPlayed)
+}
+
+func (p *Profile) updateStreak(result string) {
+	switch result {
+	case RESULT_WIN:
+		p.CurrentStreak = max(p.CurrentStreak, 0) + 1
+		p.LongestWinStreak = max(p.LongestWinStreak, p.CurrentStreak)
+	case RESULT_LOSS:
+		p.CurrentStreak = min(p.CurrentStreak, 0) - 1
+	default:
+		p.CurrentStreak = 0
+	}
+}
+
+func (r *Record) add(result string) {
+	switch result {
+	case RESULT_WIN:
+		r.Wins++
+	case RESULT_LOSS:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+}
+
+func getRecord(records map[string]*Record, name string) *Record {
+	record, ok := records[name]
+	if !ok {
+		record = &Record{}
+		records[name] = record
+	}
+	return record
+}
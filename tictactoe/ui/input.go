@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errTimeout is returned when the deadline passed before the player typed
+var errTimeout = errors.New("the time ran out")
+
+// Everything the player types is read by a single goroutine and sent to the channel.
+// A question that ran out of time stops waiting for the channel, so the next question gets the input.
+var inputChannel chan string
+
+// Input that was read but not used yet, like the column after the row
+var pendingInput string
+
+func setInput(reader io.Reader) {
+	inputChannel = make(chan string)
+	pendingInput = ""
+	go func(input chan string) {
+		// Arrows are sent as 3 bytes, so the keys are read in parts of 3 bytes
+		buf := make([]byte, 3)
+		for {
+			n, err := reader.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			input <- string(buf[:n])
+		}
+	}(inputChannel)
+}
+
+// readInput waits for more input until the deadline, a zero deadline waits forever
+func readInput(deadline time.Time) error {
+	if inputChannel == nil {
+		setInput(os.Stdin)
+	}
+
+	// Stop waiting when the deadline passed
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case part, ok := <-inputChannel:
+		if !ok {
+			return io.EOF
+		}
+		pendingInput += part
+		return nil
+	case <-timeout:
+		return errTimeout
+	}
+}
+
+// readNumber reads the next word the player typed as a number, like fmt.Scan
+func readNumber(deadline time.Time) (uint8, error) {
+	for {
+		// Find a whole word
+		pendingInput = strings.TrimLeft(pendingInput, " \t\r\n")
+		if index := strings.IndexAny(pendingInput, " \t\r\n"); index >= 0 {
+			word := pendingInput[:index]
+			pendingInput = pendingInput[index+1:]
+
+			// A word that is no number is 0, like fmt.Scan
+			number, _ := strconv.ParseUint(word, 10, 8)
+			return uint8(number), nil
+		}
+
+		// Wait for the rest of the word
+		err := readInput(deadline)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readLine waits until the player pressed ENTER, like fmt.Scanln
+func readLine() {
+	for !strings.Contains(pendingInput, "\n") {
+		if readInput(time.Time{}) != nil {
+			return
+		}
+	}
+	pendingInput = pendingInput[strings.Index(pendingInput, "\n")+1:]
+}
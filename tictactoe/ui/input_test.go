@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/tictactoe/board"
+)
+
+// prepareInput lets the test type instead of the player
+func prepareInput(t *testing.T) *io.PipeWriter {
+	reader, writer := io.Pipe()
+	setInput(reader)
+	t.Cleanup(func() {
+		writer.Close()
+		inputChannel = nil
+	})
+	return writer
+}
+
+// typeInput types the keys one by one in the background, the pipe waits until every key is read
+func typeInput(writer *io.PipeWriter, keys ...string) {
+	go func() {
+		for _, key := range keys {
+			writer.Write([]byte(key))
+		}
+	}()
+}
+
+func TestTimedOutMoveKeepsTheNextInput(t *testing.T) {
+	writer := prepareInput(t)
+	if _, _, ok := AskMove(time.Now().Add(20 * time.Millisecond)); ok {
+		t.Fatalf(`Nobody typed, the question should run out of time`)
+	}
+
+	// The ENTER after the time ran out is for the restart, not for the old question
+	typeInput(writer, "\n")
+	restarted := make(chan bool, 1)
+	go func() { restarted <- AskForRestart() }()
+	select {
+	case <-restarted:
+	case <-time.After(time.Second):
+		t.Fatalf(`The restart should get the ENTER`)
+	}
+
+	// The next move gets the next input
+	typeInput(writer, "2 1\n")
+	if row, col, ok := AskMove(time.Time{}); !ok || row != 2 || col != 1 {
+		t.Fatalf(`The next question should get the next input`)
+	}
+}
+
+func TestTimedOutCursorKeepsTheNextKey(t *testing.T) {
+	writer := prepareInput(t)
+	screen.enabled = true
+	t.Cleanup(func() { screen.enabled = false })
+	ResetScreen(nil)
+	if _, _, ok := AskMoveWithCursor(board.NewBoard(3), time.Now().Add(20*time.Millisecond)); ok {
+		t.Fatalf(`Nobody pressed a key, the question should run out of time`)
+	}
+
+	// The keys after the time ran out go to the next question
+	typeInput(writer, "d", "\r")
+	if _, column, ok := AskMoveWithCursor(board.NewBoard(3), time.Time{}); !ok || column != 1 {
+		t.Fatalf(`The next question should get the next keys`)
+	}
+}
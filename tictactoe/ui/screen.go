@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/martijnwiekens/go-learning/tictactoe/board"
+)
+
+const (
+	CLEAR_SCREEN     = "\033[H\033[2J"
+	HIDE_CURSOR      = "\033[?25l"
+	SHOW_CURSOR      = "\033[?25h"
+	ALTERNATE_SCREEN = "\033[?1049h"
+	NORMAL_SCREEN    = "\033[?1049l"
+	HIGHLIGHT_LAST   = "\033[7m"
+	HIGHLIGHT_WIN    = "\033[42m"
+)
+
+type screenState struct {
+	enabled     bool         // Whether or not the full screen mode is on
+	sttyState   string       // Terminal settings before we switched to raw mode
+	playBoard   *board.Board // Board that is shown on the screen
+	cursor      [2]uint8     // Row and column the cursor is on
+	turn        uint8        // Player that is on turn
+	lastMove    [2]int       // Row and column of the last move, -1 when there is none
+	winningLine [][2]uint8   // Places of the winning combination
+	title       string       // Line on top of the screen
+	status      string       // Line below the board
+	clocks      string       // Time left on the clock of every player
+	message     string       // Extra message, like a wrong move
+	help        string       // Keys the player can use
+}
+
+var screen = screenState{lastMove: [2]int{-1, -1}}
+
+func EnableFullScreen() error {
+	// Remember the terminal settings
+	state, err := runStty("-g")
+	if err != nil {
+		return err
+	}
+	screen.sttyState = strings.TrimSpace(state)
+
+	// Switch to raw mode, so we get every key press
+	_, err = runStty("raw", "-echo")
+	if err != nil {
+		return err
+	}
+	screen.enabled = true
+	fmt.Print(ALTERNATE_SCREEN + HIDE_CURSOR)
+	return nil
+}
+
+func DisableFullScreen() {
+	if !screen.enabled {
+		return
+	}
+
+	// Give the terminal back
+	fmt.Print(SHOW_CURSOR + NORMAL_SCREEN)
+	runStty(screen.sttyState)
+	screen.enabled = false
+}
+
+func IsFullScreen() bool {
+	return screen.enabled
+}
+
+func ResetScreen(playBoard *board.Board) {
+	screen.playBoard = playBoard
+	screen.cursor = [2]uint8{0, 0}
+	screen.lastMove = [2]int{-1, -1}
+	screen.winningLine = nil
+	screen.status = ""
+	screen.clocks = ""
+	screen.help = ""
+}
+
+func SetWinningLine(line [][2]uint8) {
+	screen.winningLine = line
+}
+
+func PrintMove(playBoard *board.Board, row uint8, col uint8) {
+	// Remember the move
+	screen.lastMove = [2]int{int(row), int(col)}
+	if !screen.enabled {
+		return
+	}
+	screen.playBoard = playBoard
+	renderScreen()
+}
+
+// AskMoveWithCursor waits for the player to place a piece, ok is false when the deadline passed first
+func AskMoveWithCursor(playBoard *board.Board, deadline time.Time) (row uint8, col uint8, ok bool) {
+	// Show the board with the cursor
+	screen.playBoard = playBoard
+	screen.help = "ARROWS move   ENTER place   Q quit"
+	renderScreen()
+
+	// Wait for the player to place a piece
+	lastIndex := uint8(playBoard.GetBoardSize() - 1)
+	for {
+		switch readKey(deadline) {
+		case "UP":
+			if screen.cursor[0] > 0 {
+				screen.cursor[0]--
+			}
+		case "DOWN":
+			if screen.cursor[0] < lastIndex {
+				screen.cursor[0]++
+			}
+		case "LEFT":
+			if screen.cursor[1] > 0 {
+				screen.cursor[1]--
+			}
+		case "RIGHT":
+			if screen.cursor[1] < lastIndex {
+				screen.cursor[1]++
+			}
+		case "ENTER":
+			screen.message = ""
+			screen.help = ""
+			return screen.cursor[0], screen.cursor[1], true
+		case "TIMEOUT":
+			screen.help = ""
+			return 0, 0, false
+		case "QUIT":
+			quitGame()
+		}
+		renderScreen()
+	}
+}
+
+func waitForEnter() {
+	for {
+		switch readKey(time.Time{}) {
+		case "ENTER":
+			return
+		case "QUIT":
+			quitGame()
+		}
+	}
+}
+
+func quitGame() {
+	DisableFullScreen()
+	os.Exit(0)
+}
+
+func readKey(deadline time.Time) string {
+	// Read a single key press, arrows are sent as 3 bytes
+	if pendingInput == "" {
+		err := readInput(deadline)
+		if err == errTimeout {
+			return "TIMEOUT"
+		} else if err != nil {
+			return "QUIT"
+		}
+	}
+	buf := []byte(pendingInput)
+	n := len(buf)
+	pendingInput = ""
+	if n == 0 {
+		return ""
+	}
+	if n == 3 && buf[0] == 27 && buf[1] == '[' {
+		switch buf[2] {
+		case 'A':
+			return "UP"
+		case 'B':
+			return "DOWN"
+		case 'C':
+			return "RIGHT"
+		case 'D':
+			return "LEFT"
+		}
+		return ""
+	}
+	switch buf[0] {
+	case '\r', '\n', ' ':
+		return "ENTER"
+	case 'q', 'Q', 3:
+		return "QUIT"
+	case 'w', 'W':
+		return "UP"
+	case 's', 'S':
+		return "DOWN"
+	case 'd', 'D':
+		return "RIGHT"
+	case 'a', 'A':
+		return "LEFT"
+	}
+	return ""
+}
+
+func renderScreen() {
+	var output []string
+	output = append(output, screen.title)
+
+	// Check if we have a board
+	if screen.playBoard != nil {
+		playBoard := screen.playBoard.GetBoard()
+		boardSize := len(playBoard)
+
+		// Create col header line
+		headerLine := "  |__"
+		for i := 0; i < boardSize; i++ {
+			headerLine += fmt.Sprintf("%d__|__", i)
+		}
+		output = append(output, "", headerLine)
+
+		// Print board
+		for i := 0; i < boardSize; i++ {
+			line := fmt.Sprintf("%d |", i)
+			for j := 0; j < boardSize; j++ {
+				line += " " + getCell(uint8(i), uint8(j)) + " |"
+			}
+			output = append(output, line)
+		}
+	}
+
+	// Add the text below the board
+	output = append(output, "", screen.status, screen.clocks, screen.message, "", screen.help)
+
+	// Raw mode needs a carriage return on every line
+	fmt.Print(CLEAR_SCREEN + strings.Join(output, "\r\n"))
+}
+
+func getCell(row uint8, col uint8) string {
+	// Find the player on this place
+	symbol := GetPlayerSymbol(screen.playBoard.GetPosition(row, col))
+
+	// Show the cursor around the place
+	left, right := " ", " "
+	if screen.help != "" && screen.cursor == [2]uint8{row, col} {
+		left, right = "[", "]"
+	}
+
+	// Find the highlight
+	highlight := ""
+	if screen.lastMove[0] == int(row) && screen.lastMove[1] == int(col) {
+		highlight = HIGHLIGHT_LAST
+	}
+	for _, place := range screen.winningLine {
+		if place == [2]uint8{row, col} {
+			highlight = HIGHLIGHT_WIN
+		}
+	}
+	if highlight == "" {
+		return left + symbol + right
+	}
+	return highlight + left + symbol + highlight + right + COLOR_RESET
+}
+
+func runStty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}